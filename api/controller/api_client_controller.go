@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type APIClientController interface {
+	GetAPIClients(c *gin.Context)
+	GetAPIClient(c *gin.Context)
+	CreateAPIClient(c *gin.Context)
+	RotateAPIClientKey(c *gin.Context)
+	RevokeAPIClient(c *gin.Context)
+}
+
+type apiClientController struct {
+	apiClientService service.APIClientService
+}
+
+func NewAPIClientController(s service.APIClientService) APIClientController {
+	return apiClientController{
+		apiClientService: s,
+	}
+}
+
+// GetAPIClients returns all the API clients including the revoked ones
+func (a apiClientController) GetAPIClients(c *gin.Context) {
+	clients, err := a.apiClientService.GetAPIClients(c.Request.Context())
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, clients)
+}
+
+// GetAPIClient returns the API client of the given id
+func (a apiClientController) GetAPIClient(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	client, err := a.apiClientService.GetAPIClient(c.Request.Context(), id)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// CreateAPIClient issues a key to a new API client, the key is only returned in this response
+func (a apiClientController) CreateAPIClient(c *gin.Context) {
+	var client model.APIClient
+	if err := c.ShouldBindJSON(&client); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	client, err := a.apiClientService.CreateAPIClient(c.Request.Context(), model.APIClient{Name: client.Name, Scopes: client.Scopes})
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, client)
+}
+
+// RotateAPIClientKey issues a new key to the API client of the given id, the previous key can't be used anymore
+func (a apiClientController) RotateAPIClientKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	client, err := a.apiClientService.RotateAPIClientKey(c.Request.Context(), id)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// RevokeAPIClient revokes the API client of the given id, which is kept for auditing
+func (a apiClientController) RevokeAPIClient(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	client, err := a.apiClientService.RevokeAPIClient(c.Request.Context(), id)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
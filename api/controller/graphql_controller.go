@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/Gohelraj/youtube-search-api/api/graphql"
+	"github.com/Gohelraj/youtube-search-api/api/middleware"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"net/http"
+)
+
+type GraphQLController interface {
+	Query(c *gin.Context)
+}
+
+type graphQLController struct {
+	schema          gql.Schema
+	takeSearchToken func(c *gin.Context) error
+}
+
+// NewGraphQLController creates the controller of the GraphQL queries, whose search fields take a token of the search
+// budget of the request with takeSearchToken
+func NewGraphQLController(schema gql.Schema, takeSearchToken func(c *gin.Context) error) GraphQLController {
+	return graphQLController{
+		schema:          schema,
+		takeSearchToken: takeSearchToken,
+	}
+}
+
+// Query executes the GraphQL request of the JSON body of a POST request, or of the query params of a GET request.
+// The errors of the query are sent in the errors of the GraphQL response with 200 OK as usual for GraphQL.
+func (g graphQLController) Query(c *gin.Context) {
+	var request graphql.Request
+	if c.Request.Method == http.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				er.SendError(c, er.ErrInvalidRequestBody)
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	if request.Query == "" {
+		er.SendError(c, er.ErrGraphQLQueryRequired)
+		return
+	}
+	ctx := c.Request.Context()
+	if client, ok := middleware.GetAPIClient(c); ok {
+		ctx = graphql.WithScopeCheck(ctx, client.HasScope)
+	}
+	ctx = graphql.WithSearchRateLimit(ctx, func() error {
+		return g.takeSearchToken(c)
+	})
+	c.JSON(http.StatusOK, graphql.Execute(ctx, g.schema, request))
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/Gohelraj/youtube-search-api/config"
+	"github.com/Gohelraj/youtube-search-api/pkg/health"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+)
+
+type HealthController interface {
+	Livez(c *gin.Context)
+	Readyz(c *gin.Context)
+}
+
+type healthController struct {
+	readinessChecks map[string]health.Check
+}
+
+func NewHealthController(readinessChecks map[string]health.Check) HealthController {
+	return healthController{
+		readinessChecks: readinessChecks,
+	}
+}
+
+// Livez reports that the server is alive, without checking its dependencies, so that it is not restarted when a
+// dependency is down
+func (h healthController) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Readyz reports the status of each dependency of the server, with 503 Service Unavailable when any of them fails
+func (h healthController) Readyz(c *gin.Context) {
+	report := health.Run(c.Request.Context(), h.readinessChecks, config.Conf.Health.CheckTimeout)
+	if !report.Healthy() {
+		slog.WarnContext(c.Request.Context(), "Readiness check failed", "components", report.Components)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
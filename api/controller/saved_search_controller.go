@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/middleware"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SavedSearchController interface {
+	GetSavedSearches(c *gin.Context)
+	GetSavedSearch(c *gin.Context)
+	CreateSavedSearch(c *gin.Context)
+	UpdateSavedSearch(c *gin.Context)
+	DeleteSavedSearch(c *gin.Context)
+	GetNewVideos(c *gin.Context)
+}
+
+type savedSearchController struct {
+	savedSearchService service.SavedSearchService
+}
+
+func NewSavedSearchController(s service.SavedSearchService) SavedSearchController {
+	return savedSearchController{
+		savedSearchService: s,
+	}
+}
+
+// GetSavedSearches returns the saved searches of the authenticated client, or of the owner query param if given when
+// the authentication is disabled
+func (s savedSearchController) GetSavedSearches(c *gin.Context) {
+	owner := clientOwner(c)
+	if owner == "" {
+		owner = c.Query("owner")
+	}
+	savedSearches, err := s.savedSearchService.GetSavedSearches(c.Request.Context(), owner)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, savedSearches)
+}
+
+// GetSavedSearch returns the saved search of the given id
+func (s savedSearchController) GetSavedSearch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	savedSearch, err := s.savedSearchService.GetSavedSearch(c.Request.Context(), id, clientOwner(c))
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, savedSearch)
+}
+
+// CreateSavedSearch saves a search, which matches the videos inserted after it is created. The owner is the
+// authenticated client when the authentication is enabled.
+func (s savedSearchController) CreateSavedSearch(c *gin.Context) {
+	var savedSearch model.SavedSearch
+	if err := c.ShouldBindJSON(&savedSearch); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	if owner := clientOwner(c); owner != "" {
+		savedSearch.Owner = owner
+	}
+	savedSearch, err := s.savedSearchService.CreateSavedSearch(c.Request.Context(), savedSearch)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, savedSearch)
+}
+
+// UpdateSavedSearch replaces the name, query, filters and owner of the saved search of the given id
+func (s savedSearchController) UpdateSavedSearch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	var savedSearch model.SavedSearch
+	if err := c.ShouldBindJSON(&savedSearch); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	savedSearch.ID = id
+	owner := clientOwner(c)
+	if owner != "" {
+		savedSearch.Owner = owner
+	}
+	savedSearch, err = s.savedSearchService.UpdateSavedSearch(c.Request.Context(), savedSearch, owner)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, savedSearch)
+}
+
+// DeleteSavedSearch deletes the saved search of the given id along with its matches
+func (s savedSearchController) DeleteSavedSearch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	if err := s.savedSearchService.DeleteSavedSearch(c.Request.Context(), id, clientOwner(c)); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetNewVideos returns the videos matching the saved search of the given id which were not returned before
+func (s savedSearchController) GetNewVideos(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		er.SendError(c, er.ErrInvalidValueInLimit)
+		return
+	}
+	if limit > 100 {
+		er.SendError(c, er.ErrLimitExceeded)
+		return
+	}
+	videos, err := s.savedSearchService.GetNewVideos(c.Request.Context(), id, clientOwner(c), limit)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, videos)
+}
+
+// clientOwner returns the owner of the saved searches of the authenticated client, which only has access to its own
+// saved searches. It is empty when the authentication is disabled, so that the owner is the one of the requests.
+func clientOwner(c *gin.Context) string {
+	client, ok := middleware.GetAPIClient(c)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("api-client:%d", client.ID)
+}
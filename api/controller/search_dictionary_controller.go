@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SearchDictionaryController interface {
+	GetSynonyms(c *gin.Context)
+	GetSynonym(c *gin.Context)
+	CreateSynonym(c *gin.Context)
+	UpdateSynonym(c *gin.Context)
+	DeleteSynonym(c *gin.Context)
+	GetStopwords(c *gin.Context)
+	CreateStopword(c *gin.Context)
+	DeleteStopword(c *gin.Context)
+}
+
+type searchDictionaryController struct {
+	searchDictionaryService service.SearchDictionaryService
+}
+
+func NewSearchDictionaryController(s service.SearchDictionaryService) SearchDictionaryController {
+	return searchDictionaryController{
+		searchDictionaryService: s,
+	}
+}
+
+// GetSynonyms returns all the search synonyms
+func (d searchDictionaryController) GetSynonyms(c *gin.Context) {
+	synonyms, err := d.searchDictionaryService.GetSynonyms(c.Request.Context())
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, synonyms)
+}
+
+// GetSynonym returns the search synonym of the given id
+func (d searchDictionaryController) GetSynonym(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	synonym, err := d.searchDictionaryService.GetSynonym(c.Request.Context(), id)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, synonym)
+}
+
+// CreateSynonym creates synonyms of a search term
+func (d searchDictionaryController) CreateSynonym(c *gin.Context) {
+	var synonym model.SearchSynonym
+	if err := c.ShouldBindJSON(&synonym); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	synonym, err := d.searchDictionaryService.CreateSynonym(c.Request.Context(), synonym)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, synonym)
+}
+
+// UpdateSynonym replaces the term and synonyms of the search synonym of the given id
+func (d searchDictionaryController) UpdateSynonym(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	var synonym model.SearchSynonym
+	if err := c.ShouldBindJSON(&synonym); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	synonym.ID = id
+	synonym, err = d.searchDictionaryService.UpdateSynonym(c.Request.Context(), synonym)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, synonym)
+}
+
+// DeleteSynonym deletes the search synonym of the given id
+func (d searchDictionaryController) DeleteSynonym(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	if err := d.searchDictionaryService.DeleteSynonym(c.Request.Context(), id); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetStopwords returns all the search stopwords
+func (d searchDictionaryController) GetStopwords(c *gin.Context) {
+	stopwords, err := d.searchDictionaryService.GetStopwords(c.Request.Context())
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stopwords)
+}
+
+// CreateStopword adds a search stopword, videos must be reindexed for it to be removed from the already indexed videos
+func (d searchDictionaryController) CreateStopword(c *gin.Context) {
+	var stopword model.SearchStopword
+	if err := c.ShouldBindJSON(&stopword); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	stopword, err := d.searchDictionaryService.CreateStopword(c.Request.Context(), stopword.Word)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, stopword)
+}
+
+// DeleteStopword deletes the search stopword, videos must be reindexed for it to be indexed in the already indexed videos
+func (d searchDictionaryController) DeleteStopword(c *gin.Context) {
+	if err := d.searchDictionaryService.DeleteStopword(c.Request.Context(), c.Param("word")); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
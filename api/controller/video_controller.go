@@ -1,48 +1,174 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"github.com/Gohelraj/youtube-search-api/api/model"
 	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/config"
 	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/export"
+	"github.com/Gohelraj/youtube-search-api/pkg/feed"
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type VideoController interface {
 	GetVideos(c *gin.Context)
+	ExportVideos(c *gin.Context)
+	GetSimilarVideos(c *gin.Context)
 	SearchVideos(c *gin.Context)
+	SuggestVideos(c *gin.Context)
+	StreamVideos(c *gin.Context)
+	GetVideosFeed(c *gin.Context)
+	SearchVideosFeed(c *gin.Context)
 }
 
 type videoController struct {
 	videoService service.VideoService
+	// stopping is closed once the server is shutting down, so that the streams end instead of blocking the shutdown
+	stopping <-chan struct{}
 }
 
-func NewVideoController(s service.VideoService) VideoController {
+func NewVideoController(s service.VideoService, stopping <-chan struct{}) VideoController {
 	return videoController{
 		videoService: s,
+		stopping:     stopping,
 	}
 }
 
 // GetVideos returns videos from the database
 func (v videoController) GetVideos(c *gin.Context) {
-	limitQueryParam := c.DefaultQuery("limit", "50")
+	limitQueryParam := c.DefaultQuery("limit", strconv.Itoa(service.DefaultPageSize))
 	limit, err := strconv.Atoi(limitQueryParam)
 	if err != nil {
 		er.SendError(c, er.ErrInvalidValueInLimit)
 		return
 	}
-	if limit > 100 {
-		er.SendError(c, er.ErrLimitExceeded)
-		return
-	}
 	offsetQueryParam := c.DefaultQuery("offset", "0")
 	offset, err := strconv.Atoi(offsetQueryParam)
 	if err != nil {
 		er.SendError(c, er.ErrInvalidValueInOffset)
 		return
 	}
-	videos, err := v.videoService.GetVideos(limit, offset)
+	collapse, err := strconv.ParseBool(c.DefaultQuery("collapse", "false"))
+	if err != nil {
+		er.SendError(c, er.ErrInvalidValueInCollapse)
+		return
+	}
+	if err := service.ValidatePage(limit, offset); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	videos, err := v.videoService.GetVideos(c.Request.Context(), model.GetVideosRequest{Limit: limit, Offset: offset, Collapse: collapse})
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, videos)
+}
+
+// exportFlushInterval is the number of exported videos after which the response is flushed to the client
+const exportFlushInterval = 500
+
+// ExportVideos streams the videos as NDJSON or CSV, all of them unless a limit is given. The export is truncated once
+// the server is shutting down.
+// Parquet exports are only written by the export command, as parquet files can't be read until they are complete.
+func (v videoController) ExportVideos(c *gin.Context) {
+	format := c.DefaultQuery("format", export.FormatNDJSON)
+	if format != export.FormatNDJSON && format != export.FormatCSV {
+		er.SendError(c, er.ErrInvalidExportFormat)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		er.SendError(c, er.ErrInvalidValueInLimit)
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		er.SendError(c, er.ErrInvalidValueInOffset)
+		return
+	}
+	collapse, err := strconv.ParseBool(c.DefaultQuery("collapse", "false"))
+	if err != nil {
+		er.SendError(c, er.ErrInvalidValueInCollapse)
+		return
+	}
+	writer, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.Header("Content-Type", export.ContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="videos.%s"`, format))
+	exported := 0
+	err = v.videoService.ExportVideos(c.Request.Context(), model.GetVideosRequest{Limit: limit, Offset: offset, Collapse: collapse}, func(video model.VideoMetadata) error {
+		select {
+		case <-v.stopping:
+			return er.ErrShuttingDown
+		default:
+		}
+		if err := writer.Write(video); err != nil {
+			return err
+		}
+		exported++
+		if exported%exportFlushInterval == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			er.SendError(c, err)
+			return
+		}
+		// the status is already sent, so the client only sees a truncated export
+		slog.ErrorContext(c.Request.Context(), "Error exporting videos", "exported", exported, "err", err)
+	}
+}
+
+// GetSimilarVideos returns videos related to the given video
+func (v videoController) GetSimilarVideos(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		er.SendError(c, er.ErrInvalidValueInLimit)
+		return
+	}
+	if limit > 100 {
+		er.SendError(c, er.ErrLimitExceeded)
+		return
+	}
+	var publishedAfter, publishedBefore time.Time
+	if value := c.Query("publishedAfter"); value != "" {
+		if publishedAfter, err = time.Parse(time.RFC3339, value); err != nil {
+			er.SendError(c, er.ErrInvalidPublishedAfter)
+			return
+		}
+	}
+	if value := c.Query("publishedBefore"); value != "" {
+		if publishedBefore, err = time.Parse(time.RFC3339, value); err != nil {
+			er.SendError(c, er.ErrInvalidPublishedBefore)
+			return
+		}
+	}
+	// published_at is stored in UTC without time zone
+	videos, err := v.videoService.GetSimilarVideos(c.Request.Context(), c.Param("youtubeId"), limit, publishedAfter.UTC(), publishedBefore.UTC())
 	if err != nil {
 		er.SendError(c, err)
 		return
@@ -53,7 +179,7 @@ func (v videoController) GetVideos(c *gin.Context) {
 // SearchVideos searches videos from database based on the search string
 func (v videoController) SearchVideos(c *gin.Context) {
 	var searchRequest model.SearchVideosRequest
-	if err := c.BindJSON(&searchRequest); err != nil {
+	if err := c.ShouldBindJSON(&searchRequest); err != nil {
 		er.SendError(c, err)
 		return
 	}
@@ -61,10 +187,241 @@ func (v videoController) SearchVideos(c *gin.Context) {
 		er.SendError(c, er.ErrSearchStringRequired)
 		return
 	}
-	videos, err := v.videoService.SearchVideos(searchRequest.SearchString)
+	if searchRequest.Limit == 0 {
+		searchRequest.Limit = service.DefaultPageSize
+	}
+	if err := service.ValidatePage(searchRequest.Limit, searchRequest.Offset); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	searchResponse, err := v.videoService.SearchVideos(c.Request.Context(), searchRequest)
 	if err != nil {
 		er.SendError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, videos)
+	// the response is the array of the videos as it used to be, unless the client opts in to the spelling suggestion
+	// or the facets, which are only in the response object
+	if !searchRequest.Fuzzy && len(searchRequest.Facets) == 0 {
+		c.JSON(http.StatusOK, searchResponse.Videos)
+		return
+	}
+	c.JSON(http.StatusOK, searchResponse)
+}
+
+// SuggestVideos returns autocomplete suggestions for the partially typed search string
+func (v videoController) SuggestVideos(c *gin.Context) {
+	searchString := c.Query("q")
+	if searchString == "" {
+		er.SendError(c, er.ErrQueryRequired)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit < 1 {
+		er.SendError(c, er.ErrInvalidValueInLimit)
+		return
+	}
+	if limit > 10 {
+		er.SendError(c, er.ErrSuggestLimitExceeded)
+		return
+	}
+	suggestions, err := v.videoService.SuggestVideos(c.Request.Context(), searchString, limit)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// streamHeartbeatInterval is the interval of the comments sent to keep idle streams open through proxies
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamVideos streams the newly inserted videos matching the optional search filter as server-sent events,
+// resuming after the video of the Last-Event-ID header when a client reconnects
+func (v videoController) StreamVideos(c *gin.Context) {
+	watchRequest := model.WatchVideosRequest{
+		Query: c.Query("q"),
+		Filters: model.VideoFilters{
+			Language:  optionalQuery(c, "language"),
+			ChannelID: optionalQuery(c, "channelId"),
+			Keyword:   optionalQuery(c, "keyword"),
+		},
+	}
+	// the lastEventId query param is for the clients which can't set headers on the first connection
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	if lastEventID != "" {
+		afterID, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || afterID < 0 {
+			er.SendError(c, er.ErrInvalidLastEventID)
+			return
+		}
+		watchRequest.AfterID = &afterID
+	}
+	videos, err := v.videoService.WatchNewVideos(c.Request.Context(), watchRequest)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// disables response buffering of nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case video, ok := <-videos:
+			if !ok {
+				// the client reconnects and resumes from the last received video
+				return
+			}
+			c.Render(-1, sse.Event{Id: strconv.FormatInt(video.ID, 10), Event: "video", Data: video})
+		case <-heartbeat.C:
+			_, _ = c.Writer.WriteString(": heartbeat\n\n")
+		case <-v.stopping:
+			// the client reconnects to another replica and resumes from the last received video
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// optionalQuery returns the value of the query param, or nil if it is not given
+func optionalQuery(c *gin.Context, key string) *string {
+	if value, ok := c.GetQuery(key); ok {
+		return &value
+	}
+	return nil
+}
+
+// GetVideosFeed returns the Atom or RSS feed of the latest published videos
+func (v videoController) GetVideosFeed(c *gin.Context) {
+	limit, ok := feedLimit(c)
+	if !ok {
+		return
+	}
+	videos, err := v.videoService.GetLatestVideos(c.Request.Context(), "", model.VideoFilters{}, limit)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	sendFeed(c, feed.Feed{Title: "YouTube videos", Description: "Latest YouTube videos", Videos: videos})
+}
+
+// SearchVideosFeed returns the Atom or RSS feed of the latest published videos matching the search string
+func (v videoController) SearchVideosFeed(c *gin.Context) {
+	searchString := c.Query("q")
+	if strings.TrimSpace(searchString) == "" {
+		er.SendError(c, er.ErrQueryRequired)
+		return
+	}
+	limit, ok := feedLimit(c)
+	if !ok {
+		return
+	}
+	filters := model.VideoFilters{Language: optionalQuery(c, "language")}
+	videos, err := v.videoService.GetLatestVideos(c.Request.Context(), searchString, filters, limit)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	title := fmt.Sprintf("YouTube videos matching %q", searchString)
+	sendFeed(c, feed.Feed{Title: title, Description: "Latest " + title, Videos: videos})
+}
+
+// feedLimit returns the limit query param of a feed, an error is sent if it is invalid
+func feedLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		er.SendError(c, er.ErrInvalidValueInLimit)
+		return 0, false
+	}
+	if limit > 100 {
+		er.SendError(c, er.ErrLimitExceeded)
+		return 0, false
+	}
+	return limit, true
+}
+
+// sendFeed sends the feed as RSS if the route ends with ".rss", and as Atom otherwise. The feed is validated by
+// its ETag and last modified time, so that the unchanged feed is not sent again to the feed readers polling it.
+func sendFeed(c *gin.Context, f feed.Feed) {
+	f.SelfURL = requestURL(c)
+	render, contentType := feed.Atom, "application/atom+xml; charset=utf-8"
+	if strings.HasSuffix(c.FullPath(), ".rss") {
+		render, contentType = feed.RSS, "application/rss+xml; charset=utf-8"
+	}
+	body, err := render(f)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	checksum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(checksum[:16]) + `"`
+	c.Header("ETag", etag)
+	updated := f.Updated()
+	if !updated.IsZero() {
+		c.Header("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+	if isNotModified(c, etag, updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// isNotModified returns whether the conditional request is satisfied by the representation of the given ETag and
+// last modified time, If-Modified-Since is ignored when If-None-Match is given as RFC 7232 requires
+func isNotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	// HTTP dates have a precision of seconds
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// requestURL returns the absolute URL of the request, as seen by the client when behind a proxy. The scheme is only
+// taken from the X-Forwarded-Proto header of the trusted proxies, so that clients can't spoof the links of the feeds.
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwardedProto := c.GetHeader("X-Forwarded-Proto"); (forwardedProto == "http" || forwardedProto == "https") && isTrustedProxy(c.RemoteIP()) {
+		scheme = forwardedProto
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.RequestURI()
+}
+
+// isTrustedProxy reports whether the IP is one of the trusted proxies, which are validated when the config is loaded
+func isTrustedProxy(ip string) bool {
+	remoteIP := net.ParseIP(ip)
+	if remoteIP == nil {
+		return false
+	}
+	for _, proxy := range config.Conf.RateLimit.TrustedProxies {
+		if proxyIP := net.ParseIP(proxy); proxyIP != nil {
+			if proxyIP.Equal(remoteIP) {
+				return true
+			}
+		} else if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(remoteIP) {
+			return true
+		}
+	}
+	return false
 }
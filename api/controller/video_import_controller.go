@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/importer"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type VideoImportController interface {
+	ImportVideos(c *gin.Context)
+}
+
+type videoImportController struct {
+	videoImportService service.VideoImportService
+}
+
+func NewVideoImportController(s service.VideoImportService) VideoImportController {
+	return videoImportController{
+		videoImportService: s,
+	}
+}
+
+// ImportVideos imports the videos of the NDJSON or CSV request body, the format defaults to the content type of the body
+func (v videoImportController) ImportVideos(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = importer.FormatNDJSON
+		if c.ContentType() == "text/csv" {
+			format = importer.FormatCSV
+		}
+	}
+	if format != importer.FormatNDJSON && format != importer.FormatCSV {
+		er.SendError(c, er.ErrInvalidImportFormat)
+		return
+	}
+	enrich, err := strconv.ParseBool(c.DefaultQuery("enrich", "false"))
+	if err != nil {
+		er.SendError(c, er.ErrInvalidValueInEnrich)
+		return
+	}
+	reader, err := importer.NewReader(format, c.Request.Body)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidImportFile)
+		return
+	}
+	result, err := v.videoImportService.ImportVideos(c.Request.Context(), reader, enrich)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
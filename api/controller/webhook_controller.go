@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type WebhookController interface {
+	GetWebhooks(c *gin.Context)
+	GetWebhook(c *gin.Context)
+	CreateWebhook(c *gin.Context)
+	UpdateWebhook(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	GetDelivery(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type webhookController struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookController(s service.WebhookService) WebhookController {
+	return webhookController{
+		webhookService: s,
+	}
+}
+
+// GetWebhooks returns all the webhooks
+func (w webhookController) GetWebhooks(c *gin.Context) {
+	webhooks, err := w.webhookService.GetWebhooks(c.Request.Context())
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook returns the webhook of the given id
+func (w webhookController) GetWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	webhook, err := w.webhookService.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// CreateWebhook subscribes a webhook to the newly inserted videos
+func (w webhookController) CreateWebhook(c *gin.Context) {
+	var webhook model.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	webhook, err := w.webhookService.CreateWebhook(c.Request.Context(), webhook)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// UpdateWebhook replaces the URL, secret, query and filters of the webhook of the given id
+func (w webhookController) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	var webhook model.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	webhook.ID = id
+	webhook, err = w.webhookService.UpdateWebhook(c.Request.Context(), webhook)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook deletes the webhook of the given id along with its deliveries
+func (w webhookController) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	if err := w.webhookService.DeleteWebhook(c.Request.Context(), id); err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries returns the delivery logs of the webhook of the given id, latest first
+func (w webhookController) GetDeliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		er.SendError(c, er.ErrInvalidValueInLimit)
+		return
+	}
+	if limit > 100 {
+		er.SendError(c, er.ErrLimitExceeded)
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		er.SendError(c, er.ErrInvalidValueInOffset)
+		return
+	}
+	deliveries, err := w.webhookService.GetDeliveries(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery returns the delivery of the webhook with its payload and attempts
+func (w webhookController) GetDelivery(c *gin.Context) {
+	id, deliveryID, ok := deliveryIDs(c)
+	if !ok {
+		return
+	}
+	delivery, err := w.webhookService.GetDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver schedules the delivery of the webhook to be attempted again
+func (w webhookController) Redeliver(c *gin.Context) {
+	id, deliveryID, ok := deliveryIDs(c)
+	if !ok {
+		return
+	}
+	delivery, err := w.webhookService.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		er.SendError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// deliveryIDs returns the webhook id and delivery id path params, an error is sent if they are invalid
+func deliveryIDs(c *gin.Context) (int64, int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidID)
+		return 0, 0, false
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		er.SendError(c, er.ErrInvalidDeliveryID)
+		return 0, 0, false
+	}
+	return id, deliveryID, true
+}
@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is a GraphQL request, sent as the JSON body of a POST request or as the query params of a GET request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Execute parses, validates and executes the request on the schema. The operations nested deeper than the maximum
+// depth or more complex than the maximum complexity are rejected before they are executed.
+func Execute(ctx context.Context, schema gql.Schema, request Request) *gql.Result {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	validation := gql.ValidateDocument(&schema, document, nil)
+	if !validation.IsValid {
+		return &gql.Result{Errors: validation.Errors}
+	}
+	cost := OperationCost(document, request.OperationName, request.Variables)
+	if cost.Depth > config.Conf.GraphQL.MaxDepth {
+		return limitExceeded(ctx, er.ErrGraphQLQueryTooDeep)
+	}
+	if cost.Complexity > config.Conf.GraphQL.MaxComplexity {
+		return limitExceeded(ctx, er.ErrGraphQLQueryTooComplex)
+	}
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+}
+
+func limitExceeded(ctx context.Context, err error) *gql.Result {
+	return &gql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(gqlerrors.NewLocatedError(resolverError(ctx, err), nil))}}
+}
+
+// extendedError is an error of the API in a GraphQL response, whose code is in the extensions of the error
+type extendedError struct {
+	err       *er.Error
+	requestID string
+}
+
+func (e extendedError) Error() string {
+	return e.err.Error()
+}
+
+func (e extendedError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.err.Code, "status": e.err.HttpStatusCode}
+	if e.err.Field != "" {
+		extensions["field"] = e.err.Field
+	}
+	if e.requestID != "" {
+		extensions["requestId"] = e.requestID
+	}
+	return extensions
+}
+
+// resolverError returns the error of a resolver, whose extensions have the code of the custom error of er.ToError
+func resolverError(ctx context.Context, err error) error {
+	return extendedError{err: er.ToError(ctx, err), requestID: logging.RequestID(ctx)}
+}
@@ -0,0 +1,202 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service/servicetest"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/graphql-go/graphql/language/parser"
+	"strings"
+	"testing"
+)
+
+func execute(t *testing.T, videoService *servicetest.FakeVideoService, ctx context.Context, query string, variables map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	config.Conf.GraphQL = config.GraphQL{MaxDepth: 8, MaxComplexity: 2000}
+	schema, err := NewSchema(videoService)
+	if err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	result := Execute(ctx, schema, Request{Query: query, Variables: variables})
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("encoding result: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	return decoded
+}
+
+// errorCodes returns the codes of the extensions of the errors of the result
+func errorCodes(result map[string]interface{}) []string {
+	var codes []string
+	errs, _ := result["errors"].([]interface{})
+	for _, err := range errs {
+		extensions, _ := err.(map[string]interface{})["extensions"].(map[string]interface{})
+		code, _ := extensions["code"].(string)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func TestVideosPagination(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(5)
+	query := `query($after: String) { videos(first: 2, after: $after) { edges { cursor node { youtubeId viewCount } } pageInfo { hasNextPage hasPreviousPage endCursor } } }`
+	result := execute(t, videoService, context.Background(), query, nil)
+	if result["errors"] != nil {
+		t.Fatalf("errors = %v", result["errors"])
+	}
+	videos := result["data"].(map[string]interface{})["videos"].(map[string]interface{})
+	edges := videos["edges"].([]interface{})
+	if len(edges) != 2 || edges[1].(map[string]interface{})["node"].(map[string]interface{})["youtubeId"] != "v1" {
+		t.Fatalf("edges = %v, want the videos v0 and v1", edges)
+	}
+	if viewCount := edges[0].(map[string]interface{})["node"].(map[string]interface{})["viewCount"]; viewCount != float64(5_000_000_000) {
+		t.Errorf("viewCount = %v, want 5000000000", viewCount)
+	}
+	pageInfo := videos["pageInfo"].(map[string]interface{})
+	if pageInfo["hasNextPage"] != true || pageInfo["hasPreviousPage"] != false {
+		t.Errorf("pageInfo = %v, want a next page and no previous page", pageInfo)
+	}
+	if videoService.VideosRequest.Limit != 3 {
+		t.Errorf("limit = %d, want one more than first", videoService.VideosRequest.Limit)
+	}
+
+	result = execute(t, videoService, context.Background(), query, map[string]interface{}{"after": pageInfo["endCursor"]})
+	videos = result["data"].(map[string]interface{})["videos"].(map[string]interface{})
+	edges = videos["edges"].([]interface{})
+	if videoService.VideosRequest.Offset != 2 || len(edges) != 2 || edges[0].(map[string]interface{})["node"].(map[string]interface{})["youtubeId"] != "v2" {
+		t.Errorf("offset = %d and edges = %v, want the videos v2 and v3", videoService.VideosRequest.Offset, edges)
+	}
+
+	result = execute(t, videoService, context.Background(), query, map[string]interface{}{"after": "not a cursor"})
+	if codes := errorCodes(result); len(codes) != 1 || codes[0] != "invalid_cursor" {
+		t.Errorf("error codes = %v, want invalid_cursor", codes)
+	}
+}
+
+func TestVideoWithChannel(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(3)
+	result := execute(t, videoService, context.Background(), `{ video(youtubeId: "v1") { title channel { id title videos(first: 2) { youtubeId } } } missing: video(youtubeId: "v9") { title } }`, nil)
+	if result["errors"] != nil {
+		t.Fatalf("errors = %v", result["errors"])
+	}
+	data := result["data"].(map[string]interface{})
+	if data["missing"] != nil {
+		t.Errorf("missing video = %v, want null", data["missing"])
+	}
+	channel := data["video"].(map[string]interface{})["channel"].(map[string]interface{})
+	if channel["id"] != "c1" || channel["title"] != "Channel 1" || len(channel["videos"].([]interface{})) != 2 {
+		t.Errorf("channel = %v, want the channel c1 with 2 videos", channel)
+	}
+	if videoService.Filters.ChannelID == nil || *videoService.Filters.ChannelID != "c1" {
+		t.Errorf("filters = %+v, want the videos of the channel c1", videoService.Filters)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(3)
+	query := `{ search(query: " golang ", filters: {language: "en", fuzzy: true}, first: 5) { edges { node { youtubeId } } pageInfo { hasNextPage } } }`
+	result := execute(t, videoService, context.Background(), query, nil)
+	if result["errors"] != nil {
+		t.Fatalf("errors = %v", result["errors"])
+	}
+	searchRequest := videoService.SearchRequest
+	if searchRequest.SearchString != "golang" || searchRequest.Language != "en" || !searchRequest.Fuzzy || searchRequest.Collapse || searchRequest.Limit != 6 {
+		t.Errorf("search request = %+v", searchRequest)
+	}
+
+	withoutSearchScope := WithScopeCheck(context.Background(), func(scope string) bool { return scope == model.ScopeRead })
+	result = execute(t, videoService, withoutSearchScope, query, nil)
+	if codes := errorCodes(result); len(codes) != 1 || codes[0] != "forbidden" {
+		t.Errorf("error codes = %v, want forbidden", codes)
+	}
+}
+
+func TestSearchRateLimit(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(3)
+	taken := 0
+	overLimit := WithSearchRateLimit(context.Background(), func() error {
+		taken++
+		return er.ErrRateLimited
+	})
+	// only the search fields take a token of the search budget
+	result := execute(t, videoService, overLimit, `{ videos { edges { cursor } } }`, nil)
+	if result["errors"] != nil || taken != 0 {
+		t.Errorf("errors = %v and %d tokens taken, want no errors and no tokens", result["errors"], taken)
+	}
+	result = execute(t, videoService, overLimit, `{ search(query: "golang") { edges { cursor } } }`, nil)
+	if codes := errorCodes(result); len(codes) != 1 || codes[0] != "rate_limited" || taken != 1 {
+		t.Errorf("error codes = %v and %d tokens taken, want rate_limited", codes, taken)
+	}
+	if videoService.SearchRequest.SearchString != "" {
+		t.Errorf("searched %q over the rate limit", videoService.SearchRequest.SearchString)
+	}
+}
+
+func TestInternalErrorsAreNotExposed(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(1)
+	videoService.Err = servicetest.ErrInternal
+	result := execute(t, videoService, context.Background(), `{ videos { edges { cursor } } }`, nil)
+	if codes := errorCodes(result); len(codes) != 1 || codes[0] != "internal_error" {
+		t.Errorf("error codes = %v, want internal_error", codes)
+	}
+	if encoded, _ := json.Marshal(result); strings.Contains(string(encoded), "relation") {
+		t.Errorf("result %s exposes the internal error", encoded)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"within limits", `{ videos(first: 10) { edges { node { title channel { videos(first: 10) { title } } } } } }`, ""},
+		{"too deep", `{ video(youtubeId: "v0") { channel { videos(first: 1) { channel { videos(first: 1) { channel { videos(first: 1) { channel { id } } } } } } } } }`, "graphql_query_too_deep"},
+		{"too complex", `{ videos(first: 100) { edges { node { channel { videos(first: 100) { title } } } } } }`, "graphql_query_too_complex"},
+		{"introspection", `{ __schema { types { name fields { name type { name ofType { name ofType { name ofType { name ofType { name } } } } } } } } }`, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := execute(t, servicetest.NewFakeVideoService(1), context.Background(), test.query, nil)
+			codes := errorCodes(result)
+			if test.code == "" && result["errors"] != nil {
+				t.Errorf("errors = %v, want none", result["errors"])
+			}
+			if test.code != "" && (len(codes) != 1 || codes[0] != test.code) {
+				t.Errorf("error codes = %v, want %s", codes, test.code)
+			}
+		})
+	}
+}
+
+func TestOperationCost(t *testing.T) {
+	query := `
+		query Latest($first: Int = 5) { videos(first: $first) { edges { ...edge } } }
+		query Search { search(query: "go") { edges { ... on VideoEdge { cursor } } } }
+		fragment edge on VideoEdge { cursor node { title } }`
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("parsing query: %v", err)
+	}
+	tests := []struct {
+		operationName string
+		variables     map[string]interface{}
+		want          Cost
+	}{
+		// videos + first * (edges + cursor + node + title)
+		{"Latest", nil, Cost{Depth: 4, Complexity: 1 + 5*4}},
+		{"Latest", map[string]interface{}{"first": float64(50)}, Cost{Depth: 4, Complexity: 1 + 50*4}},
+		{"Search", nil, Cost{Depth: 3, Complexity: 1 + defaultFirst*2}},
+	}
+	for _, test := range tests {
+		if got := OperationCost(document, test.operationName, test.variables); got != test.want {
+			t.Errorf("cost of %s with %v = %+v, want %+v", test.operationName, test.variables, got, test.want)
+		}
+	}
+}
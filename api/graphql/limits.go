@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+	"strings"
+)
+
+// Cost is the nesting depth and complexity of the fields selected by a GraphQL operation
+type Cost struct {
+	Depth      int
+	Complexity int
+}
+
+// costAnalyzer computes the cost of the selections of an operation, its fragments must be valid and acyclic
+type costAnalyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// OperationCost returns the cost of the operation of the given name of the validated document, or of its only
+// operation when the name is empty. Each field costs 1, and the fields having a first argument cost as many times as
+// the number of items it requests, as their selections are resolved for each of the items. The introspection fields
+// are not counted, so that the clients can always fetch the schema.
+func OperationCost(document *ast.Document, operationName string, variables map[string]interface{}) Cost {
+	analyzer := costAnalyzer{fragments: map[string]*ast.FragmentDefinition{}, variables: map[string]interface{}{}}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			analyzer.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || definition.Name != nil && definition.Name.Value == operationName {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return Cost{}
+	}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			analyzer.variables[definition.Variable.Name.Value] = definition.DefaultValue.GetValue()
+		}
+	}
+	for name, value := range variables {
+		analyzer.variables[name] = value
+	}
+	return analyzer.selectionSetCost(operation.SelectionSet)
+}
+
+func (a costAnalyzer) selectionSetCost(selectionSet *ast.SelectionSet) Cost {
+	cost := Cost{}
+	if selectionSet == nil {
+		return cost
+	}
+	for _, selection := range selectionSet.Selections {
+		var selectionCost Cost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			childrenCost := a.selectionSetCost(selection.SelectionSet)
+			selectionCost = Cost{
+				Depth:      childrenCost.Depth + 1,
+				Complexity: 1 + a.items(selection)*childrenCost.Complexity,
+			}
+		case *ast.InlineFragment:
+			selectionCost = a.selectionSetCost(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if fragment, ok := a.fragments[selection.Name.Value]; ok {
+				selectionCost = a.selectionSetCost(fragment.SelectionSet)
+			}
+		}
+		cost.Depth = max(cost.Depth, selectionCost.Depth)
+		cost.Complexity += selectionCost.Complexity
+	}
+	return cost
+}
+
+// items returns the number of items requested by the first argument of the field, the items of the fields having
+// no first argument are resolved once
+func (a costAnalyzer) items(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != firstArgument {
+			continue
+		}
+		var value interface{}
+		if variable, ok := argument.Value.(*ast.Variable); ok {
+			value = a.variables[variable.Name.Value]
+		} else {
+			value = argument.Value.GetValue()
+		}
+		switch value := value.(type) {
+		case string:
+			if first, err := strconv.Atoi(value); err == nil {
+				return max(first, 1)
+			}
+		case float64:
+			return max(int(value), 1)
+		case int:
+			return max(value, 1)
+		}
+		return defaultFirst
+	}
+	if connectionFields[field.Name.Value] {
+		return defaultFirst
+	}
+	return 1
+}
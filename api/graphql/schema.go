@@ -0,0 +1,351 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	gql "github.com/graphql-go/graphql"
+	"strconv"
+	"strings"
+)
+
+// Pagination of the list fields, their first argument is the number of items requested
+const (
+	firstArgument = "first"
+	defaultFirst  = 20
+)
+
+// connectionFields are the fields whose items default to defaultFirst when their first argument is not set
+var connectionFields = map[string]bool{"videos": true, "search": true}
+
+// cursorPrefix prefixes the offset of the items in their cursor, the cursors are opaque to the clients
+const cursorPrefix = "offset:"
+
+// channel is the channel of a video, resolved from the channel columns of the video
+type channel struct {
+	ID    string  `json:"id"`
+	Title *string `json:"title"`
+}
+
+// edge is an item of a connection along with its cursor
+type edge struct {
+	Cursor string              `json:"cursor"`
+	Node   model.VideoMetadata `json:"node"`
+}
+
+// connection is a page of the videos of a connection
+type connection struct {
+	Edges      []edge   `json:"edges"`
+	PageInfo   pageInfo `json:"pageInfo"`
+	DidYouMean *string  `json:"didYouMean"`
+}
+
+type pageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// schemaResolver resolves the fields of the schema with the video service
+type schemaResolver struct {
+	videoService service.VideoService
+}
+
+// NewSchema creates the GraphQL schema of the videos, their channels and the search, resolved by the video service
+func NewSchema(videoService service.VideoService) (gql.Schema, error) {
+	r := schemaResolver{videoService: videoService}
+
+	long := gql.NewScalar(gql.ScalarConfig{
+		Name:        "Long",
+		Description: "The `Long` scalar type represents a signed 64-bit integer.",
+		Serialize: func(value interface{}) interface{} {
+			switch value := value.(type) {
+			case int64:
+				return value
+			case *int64:
+				if value != nil {
+					return *value
+				}
+			}
+			return nil
+		},
+	})
+	channelType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Channel",
+		Description: "YouTube channel which published videos",
+		Fields: gql.Fields{
+			"id":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"title": &gql.Field{Type: gql.String},
+		},
+	})
+	videoType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Video",
+		Description: "YouTube video fetched for the keyword",
+		Fields: gql.Fields{
+			"id":              &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"youtubeId":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"title":           &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"description":     &gql.Field{Type: gql.String},
+			"publishedAt":     &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+			"thumbnailUrl":    &gql.Field{Type: gql.String},
+			"language":        &gql.Field{Type: gql.String},
+			"durationSeconds": &gql.Field{Type: gql.Int},
+			"keyword":         &gql.Field{Type: gql.String},
+			"viewCount":       &gql.Field{Type: long},
+			"duplicates": &gql.Field{
+				Type:        gql.Int,
+				Description: "Number of near-duplicates of the video, only set when the videos are collapsed",
+			},
+			"channel": &gql.Field{
+				Type:        channelType,
+				Description: "Channel which published the video, null when it is not known",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					video := p.Source.(model.VideoMetadata)
+					if video.ChannelID == nil {
+						return nil, nil
+					}
+					return channel{ID: *video.ChannelID, Title: video.ChannelTitle}, nil
+				},
+			},
+		},
+	})
+	// the videos of a channel refer back to the channel, so the field is added once both types exist
+	channelType.AddFieldConfig("videos", &gql.Field{
+		Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(videoType))),
+		Description: "Latest published videos of the channel",
+		Args:        gql.FieldConfigArgument{firstArgument: firstArgumentConfig()},
+		Resolve:     r.channelVideos,
+	})
+
+	pageInfoType := gql.NewObject(gql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: gql.Fields{
+			"hasNextPage":     &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"hasPreviousPage": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"startCursor":     &gql.Field{Type: gql.String},
+			"endCursor":       &gql.Field{Type: gql.String},
+		},
+	})
+	edgeType := gql.NewObject(gql.ObjectConfig{
+		Name: "VideoEdge",
+		Fields: gql.Fields{
+			"cursor": &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"node":   &gql.Field{Type: gql.NewNonNull(videoType)},
+		},
+	})
+	pageFields := gql.Fields{
+		"edges":    &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(edgeType)))},
+		"pageInfo": &gql.Field{Type: gql.NewNonNull(pageInfoType)},
+	}
+	videoConnectionType := gql.NewObject(gql.ObjectConfig{Name: "VideoConnection", Fields: pageFields})
+	searchConnectionType := gql.NewObject(gql.ObjectConfig{
+		Name: "SearchConnection",
+		Fields: gql.Fields{
+			"edges":    pageFields["edges"],
+			"pageInfo": pageFields["pageInfo"],
+			"didYouMean": &gql.Field{
+				Type:        gql.String,
+				Description: "Spelling suggestion of the query, only set by fuzzy searches having too few hits",
+			},
+		},
+	})
+	searchFiltersType := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "SearchFilters",
+		Fields: gql.InputObjectConfigFieldMap{
+			"language": &gql.InputObjectFieldConfig{Type: gql.String, Description: "Language of the query, which is stemmed using its text search configuration"},
+			"fuzzy":    &gql.InputObjectFieldConfig{Type: gql.Boolean, DefaultValue: false, Description: "Blend in the videos with titles similar to the query when there are too few hits"},
+			"collapse": &gql.InputObjectFieldConfig{Type: gql.Boolean, DefaultValue: false, Description: "Return only the best match of each cluster of near-duplicates"},
+		},
+	})
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"videos": &gql.Field{
+				Type:        gql.NewNonNull(videoConnectionType),
+				Description: "Videos in reverse chronological order of their publishing date",
+				Args: gql.FieldConfigArgument{
+					firstArgument: firstArgumentConfig(),
+					"after":       &gql.ArgumentConfig{Type: gql.String},
+					"collapse":    &gql.ArgumentConfig{Type: gql.Boolean, DefaultValue: false},
+				},
+				Resolve: r.videos,
+			},
+			"video": &gql.Field{
+				Type:        videoType,
+				Description: "Video of the YouTube id, null when it doesn't exist",
+				Args:        gql.FieldConfigArgument{"youtubeId": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+				Resolve:     r.video,
+			},
+			"search": &gql.Field{
+				Type:        gql.NewNonNull(searchConnectionType),
+				Description: "Videos matching the query ranked by relevance, requires the search scope",
+				Args: gql.FieldConfigArgument{
+					"query":       &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"filters":     &gql.ArgumentConfig{Type: searchFiltersType},
+					firstArgument: firstArgumentConfig(),
+					"after":       &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: r.search,
+			},
+		},
+	})
+	return gql.NewSchema(gql.SchemaConfig{Query: queryType})
+}
+
+func firstArgumentConfig() *gql.ArgumentConfig {
+	return &gql.ArgumentConfig{
+		Type:         gql.Int,
+		DefaultValue: defaultFirst,
+		Description:  "Number of items to return, at most " + strconv.Itoa(service.MaxPageSize),
+	}
+}
+
+func (r schemaResolver) videos(p gql.ResolveParams) (interface{}, error) {
+	first, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	// one more video than requested is fetched to know whether there is a next page
+	videos, err := r.videoService.GetVideos(p.Context, model.GetVideosRequest{Limit: first + 1, Offset: offset, Collapse: p.Args["collapse"].(bool)})
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	return newConnection(videos, first, offset), nil
+}
+
+func (r schemaResolver) video(p gql.ResolveParams) (interface{}, error) {
+	video, err := r.videoService.GetVideo(p.Context, p.Args["youtubeId"].(string))
+	if err == er.ErrVideoNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	return video, nil
+}
+
+func (r schemaResolver) search(p gql.ResolveParams) (interface{}, error) {
+	if !hasScope(p.Context, model.ScopeSearch) {
+		return nil, resolverError(p.Context, er.ErrForbidden)
+	}
+	if err := takeSearchRateLimit(p.Context); err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	first, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	searchRequest := model.SearchVideosRequest{SearchString: strings.TrimSpace(p.Args["query"].(string)), Limit: first + 1, Offset: offset}
+	if searchRequest.SearchString == "" {
+		return nil, resolverError(p.Context, er.ErrSearchQueryRequired)
+	}
+	if filters, ok := p.Args["filters"].(map[string]interface{}); ok {
+		searchRequest.Language, _ = filters["language"].(string)
+		searchRequest.Fuzzy, _ = filters["fuzzy"].(bool)
+		searchRequest.Collapse, _ = filters["collapse"].(bool)
+	}
+	response, err := r.videoService.SearchVideos(p.Context, searchRequest)
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	result := newConnection(response.Videos, first, offset)
+	result.DidYouMean = response.DidYouMean
+	return result, nil
+}
+
+func (r schemaResolver) channelVideos(p gql.ResolveParams) (interface{}, error) {
+	first, _, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	channelID := p.Source.(channel).ID
+	videos, err := r.videoService.GetLatestVideos(p.Context, "", model.VideoFilters{ChannelID: &channelID}, first)
+	if err != nil {
+		return nil, resolverError(p.Context, err)
+	}
+	return videos, nil
+}
+
+// pageArgs returns the number of items requested by the first argument and the offset of the after cursor
+func pageArgs(args map[string]interface{}) (int, int, error) {
+	first := args[firstArgument].(int)
+	if err := service.ValidatePage(first, 0); err != nil {
+		return 0, 0, er.ErrInvalidFirst
+	}
+	after, ok := args["after"].(string)
+	if !ok {
+		return first, 0, nil
+	}
+	offset, err := decodeCursor(after)
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, offset + 1, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return 0, er.ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, er.ErrInvalidCursor
+	}
+	return offset, nil
+}
+
+// newConnection returns the connection of the first videos starting at the offset, the videos must have been fetched
+// with one more video than requested, whose presence tells that there is a next page
+func newConnection(videos []model.VideoMetadata, first int, offset int) connection {
+	result := connection{Edges: []edge{}, PageInfo: pageInfo{HasNextPage: len(videos) > first, HasPreviousPage: offset > 0}}
+	for i, video := range videos {
+		if i == first {
+			break
+		}
+		result.Edges = append(result.Edges, edge{Cursor: encodeCursor(offset + i), Node: video})
+	}
+	if len(result.Edges) > 0 {
+		result.PageInfo.StartCursor = &result.Edges[0].Cursor
+		result.PageInfo.EndCursor = &result.Edges[len(result.Edges)-1].Cursor
+	}
+	return result
+}
+
+type scopeCheckKey struct{}
+
+// WithScopeCheck returns a copy of the context carrying the check of the scopes of the client, the fields requiring a
+// scope are resolved with an error when the check fails. All the fields are resolved when the context has no check.
+func WithScopeCheck(ctx context.Context, hasScope func(scope string) bool) context.Context {
+	return context.WithValue(ctx, scopeCheckKey{}, hasScope)
+}
+
+func hasScope(ctx context.Context, scope string) bool {
+	check, ok := ctx.Value(scopeCheckKey{}).(func(scope string) bool)
+	return !ok || check(scope)
+}
+
+type searchRateLimitKey struct{}
+
+// WithSearchRateLimit returns a copy of the context carrying the rate limit of the search budget, which take returns
+// an error of when the search is over the limit. Each search field takes a token before it is resolved, so that
+// searching through GraphQL has the same budget as the search routes.
+func WithSearchRateLimit(ctx context.Context, take func() error) context.Context {
+	return context.WithValue(ctx, searchRateLimitKey{}, take)
+}
+
+func takeSearchRateLimit(ctx context.Context) error {
+	take, ok := ctx.Value(searchRateLimitKey{}).(func() error)
+	if !ok {
+		return nil
+	}
+	return take()
+}
@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/grpc/videopb"
+	"github.com/Gohelraj/youtube-search-api/api/middleware"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"github.com/Gohelraj/youtube-search-api/pkg/metrics"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestIDMetadata is the metadata key carrying the id of a call, the same as the request id header of the REST API
+var requestIDMetadata = strings.ToLower(middleware.RequestIDHeader)
+
+// methodScopes are the scopes required by the methods of the video service, the same as their REST routes
+var methodScopes = map[string]string{
+	fullMethod("ListVideos"):     model.ScopeRead,
+	fullMethod("GetVideo"):       model.ScopeRead,
+	fullMethod("SearchVideos"):   model.ScopeSearch,
+	fullMethod("WatchNewVideos"): model.ScopeRead,
+}
+
+// methodBudgets are the rate limit budgets of the methods of the video service, the same as their REST routes
+var methodBudgets = map[string]string{
+	fullMethod("ListVideos"):     middleware.VideosBudget,
+	fullMethod("GetVideo"):       middleware.VideosBudget,
+	fullMethod("SearchVideos"):   middleware.SearchBudget,
+	fullMethod("WatchNewVideos"): middleware.VideosBudget,
+}
+
+func fullMethod(method string) string {
+	return "/" + videopb.VideoService_ServiceDesc.ServiceName + "/" + method
+}
+
+// Server is the gRPC server of the video service, which serves the same videos as the REST API
+type Server struct {
+	server           *gogrpc.Server
+	apiClientService service.APIClientService
+	limiter          ratelimit.Limiter
+	// stopping is closed once the server is shutting down, so that the streams end instead of blocking the shutdown
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// NewServer creates the gRPC server of the video service. The calls are traced, logged with their request id and
+// recorded in the metrics, require an API key having the scope of the method when the authentication is enabled,
+// and take a token of the rate limit budget of the method with the limiter of the REST API.
+func NewServer(videoService service.VideoService, apiClientService service.APIClientService, limiter ratelimit.Limiter) *Server {
+	s := &Server{
+		apiClientService: apiClientService,
+		limiter:          limiter,
+		stopping:         make(chan struct{}),
+	}
+	s.server = gogrpc.NewServer(
+		gogrpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), s.unaryInterceptor),
+		gogrpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), s.streamInterceptor),
+	)
+	videopb.RegisterVideoServiceServer(s.server, newVideoServer(videoService, s.stopping))
+	return s
+}
+
+// Serve serves the calls accepted by the listener until the server is shut down
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// Shutdown ends the streams with UNAVAILABLE and waits for the pending calls to finish, the server is stopped
+// right away once the context is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stopping)
+	})
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (resp interface{}, err error) {
+	ctx = withRequestID(ctx)
+	_ = gogrpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, logging.RequestID(ctx)))
+	start := time.Now()
+	defer func() {
+		err = finishCall(ctx, info.FullMethod, start, recover(), err)
+	}()
+	client, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.takeRateLimit(ctx, info.FullMethod, client); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, stream gogrpc.ServerStream, info *gogrpc.StreamServerInfo, handler gogrpc.StreamHandler) (err error) {
+	ctx := withRequestID(stream.Context())
+	_ = stream.SetHeader(metadata.Pairs(requestIDMetadata, logging.RequestID(ctx)))
+	start := time.Now()
+	defer func() {
+		err = finishCall(ctx, info.FullMethod, start, recover(), err)
+	}()
+	client, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	if err := s.takeRateLimit(ctx, info.FullMethod, client); err != nil {
+		return err
+	}
+	return handler(srv, contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// contextStream is a server stream whose context carries the request id
+type contextStream struct {
+	gogrpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// withRequestID returns a copy of the context carrying the request id of the x-request-id metadata when it is valid,
+// or a random request id
+func withRequestID(ctx context.Context) context.Context {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadata); len(values) > 0 {
+		requestID = values[0]
+	}
+	if !middleware.IsValidRequestID(requestID) {
+		requestID = middleware.NewRequestID()
+	}
+	return logging.WithRequestID(ctx, requestID)
+}
+
+// authenticate returns the client of the API key of the authorization: Bearer or x-api-key metadata, and rejects
+// the clients which don't have the scope of the method. All the calls are allowed without a client when the
+// authentication is disabled.
+func (s *Server) authenticate(ctx context.Context, method string) (*model.APIClient, error) {
+	if !config.Conf.Auth.Enabled {
+		return nil, nil
+	}
+	key := metadataAPIKey(ctx)
+	if key == "" {
+		return nil, er.ErrUnauthorized
+	}
+	client, err := s.apiClientService.Authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	scope, ok := methodScopes[method]
+	if !ok || !client.HasScope(scope) {
+		return nil, er.ErrForbidden
+	}
+	return &client, nil
+}
+
+// takeRateLimit takes a token of the budget of the method from the buckets of the API client and of the peer IP of
+// the call, which are the buckets of the REST API too. er.ErrRateLimited is returned when the call is over the limit.
+func (s *Server) takeRateLimit(ctx context.Context, method string, client *model.APIClient) error {
+	if !config.Conf.RateLimit.Enabled {
+		return nil
+	}
+	peerIP := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		peerIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(peerIP); err == nil {
+			peerIP = host
+		}
+	}
+	budget := methodBudgets[method]
+	limited, ok := ratelimit.TakeAll(ctx, s.limiter, middleware.RateLimitKeys(budget, peerIP, client), middleware.BudgetLimit(budget))
+	if ok && !limited.Allowed {
+		return er.ErrRateLimited
+	}
+	return nil
+}
+
+// metadataAPIKey returns the API key of the call, the authorization metadata is preferred over the x-api-key metadata
+func metadataAPIKey(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		if scheme, token, ok := strings.Cut(values[0], " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, "x-api-key"); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// finishCall recovers from the panic of the call, maps its error to a status error, and logs and records the call.
+// The server errors are logged at the error level and the client errors at the warn level.
+func finishCall(ctx context.Context, method string, start time.Time, panicValue interface{}, err error) error {
+	if panicValue != nil {
+		slog.ErrorContext(ctx, "panic handling call", "err", panicValue, "stack", string(debug.Stack()))
+		err = er.ErrInternal
+	}
+	if _, ok := status.FromError(err); !ok {
+		err = statusError(ctx, err)
+	}
+	code := status.Code(err)
+	duration := time.Since(start)
+	metrics.GRPCRequestDuration.WithLabelValues(method, code.String()).Observe(duration.Seconds())
+
+	level := slog.LevelWarn
+	switch code {
+	case codes.OK:
+		level = slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	}
+	attributes := []any{
+		"method", method,
+		"code", code.String(),
+		"duration_ms", duration.Milliseconds(),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attributes = append(attributes, "peer", p.Addr.String())
+	}
+	slog.Log(ctx, level, "grpc call", attributes...)
+	return err
+}
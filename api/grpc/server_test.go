@@ -0,0 +1,259 @@
+package grpc
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/grpc/videopb"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/api/service/servicetest"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAPIClientService authenticates the clients of the keys
+type fakeAPIClientService struct {
+	service.APIClientService
+	clients map[string]model.APIClient
+}
+
+func (f fakeAPIClientService) Authenticate(_ context.Context, key string) (model.APIClient, error) {
+	client, ok := f.clients[key]
+	if !ok {
+		return client, er.ErrUnauthorized
+	}
+	return client, nil
+}
+
+// startServer serves the video service on an in-memory listener and returns a client connected to it
+func startServer(t *testing.T, videoService service.VideoService, apiClientService service.APIClientService) (*Server, videopb.VideoServiceClient) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(videoService, apiClientService, ratelimit.NewMemoryLimiter())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+	})
+	conn, err := gogrpc.Dial("bufconn",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return server, videopb.NewVideoServiceClient(conn)
+}
+
+// errorReason returns the code of the status error and the reason of its ErrorInfo detail
+func errorReason(err error) (codes.Code, string) {
+	st := status.Convert(err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return st.Code(), info.Reason
+		}
+	}
+	return st.Code(), ""
+}
+
+func TestListAndGetVideos(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(3)
+	_, client := startServer(t, videoService, fakeAPIClientService{})
+	ctx := context.Background()
+
+	var header metadata.MD
+	requestCtx := metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-1")
+	response, err := client.ListVideos(requestCtx, &videopb.ListVideosRequest{Collapse: true}, gogrpc.Header(&header))
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if len(response.Videos) != 3 || response.Videos[0].YoutubeId != "v0" || response.Videos[0].GetDurationSeconds() != 90 {
+		t.Errorf("videos = %v, want the 3 videos", response.Videos)
+	}
+	if videoService.VideosRequest != (model.GetVideosRequest{Limit: 50, Collapse: true}) {
+		t.Errorf("videos request = %+v, want the default limit", videoService.VideosRequest)
+	}
+	if requestID := header.Get("x-request-id"); len(requestID) != 1 || requestID[0] != "req-1" {
+		t.Errorf("request id header = %v, want req-1", requestID)
+	}
+
+	_, err = client.ListVideos(ctx, &videopb.ListVideosRequest{Limit: 101})
+	if code, reason := errorReason(err); code != codes.InvalidArgument || reason != "limit_exceeded" {
+		t.Errorf("ListVideos() error = %v, want InvalidArgument limit_exceeded", err)
+	}
+	var field string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			field = badRequest.FieldViolations[0].Field
+		}
+	}
+	if field != "limit" {
+		t.Errorf("field violation = %q, want limit", field)
+	}
+
+	video, err := client.GetVideo(ctx, &videopb.GetVideoRequest{YoutubeId: "v1"})
+	if err != nil || video.Title != "Video 1" || !video.PublishedAt.AsTime().Equal(videoService.Videos[1].PublishedAt) {
+		t.Errorf("GetVideo() = %v, %v, want the video v1", video, err)
+	}
+	_, err = client.GetVideo(ctx, &videopb.GetVideoRequest{YoutubeId: "v9"})
+	if code, reason := errorReason(err); code != codes.NotFound || reason != "video_not_found" {
+		t.Errorf("GetVideo() error = %v, want NotFound video_not_found", err)
+	}
+}
+
+func TestSearchVideos(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(2)
+	_, client := startServer(t, videoService, fakeAPIClientService{})
+	ctx := context.Background()
+
+	response, err := client.SearchVideos(ctx, &videopb.SearchVideosRequest{Query: " golang ", Language: "en", Facets: []string{model.FacetChannel}, RankingProfile: "fresh"})
+	if err != nil {
+		t.Fatalf("SearchVideos() error = %v", err)
+	}
+	searchRequest := videoService.SearchRequest
+	if searchRequest.SearchString != "golang" || searchRequest.Limit != 50 || searchRequest.Language != "en" || searchRequest.Ranking == nil || searchRequest.Ranking.Profile != "fresh" {
+		t.Errorf("search request = %+v", searchRequest)
+	}
+	buckets := response.Facets[model.FacetChannel].GetBuckets()
+	if len(response.Videos) != 2 || len(buckets) != 1 || buckets[0].GetLabel() != "Channel 1" || buckets[0].Count != 2 {
+		t.Errorf("response = %v, want 2 videos and the channel facet", response)
+	}
+
+	tests := []struct {
+		name    string
+		request *videopb.SearchVideosRequest
+		reason  string
+	}{
+		{"missing query", &videopb.SearchVideosRequest{Query: " "}, "search_query_required"},
+		{"negative offset", &videopb.SearchVideosRequest{Query: "go", Offset: -1}, "invalid_offset"},
+	}
+	for _, test := range tests {
+		_, err := client.SearchVideos(ctx, test.request)
+		if code, reason := errorReason(err); code != codes.InvalidArgument || reason != test.reason {
+			t.Errorf("%s: SearchVideos() error = %v, want InvalidArgument %s", test.name, err, test.reason)
+		}
+	}
+}
+
+func TestInternalErrorsAreNotExposed(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(1)
+	videoService.Err = servicetest.ErrInternal
+	_, client := startServer(t, videoService, fakeAPIClientService{})
+	_, err := client.ListVideos(context.Background(), &videopb.ListVideosRequest{})
+	if code, reason := errorReason(err); code != codes.Internal || reason != "internal_error" {
+		t.Errorf("ListVideos() error = %v, want Internal internal_error", err)
+	}
+	if strings.Contains(err.Error(), "relation") {
+		t.Errorf("error %v exposes the internal error", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	config.Conf.Auth.Enabled = true
+	defer func() { config.Conf.Auth.Enabled = false }()
+	apiClientService := fakeAPIClientService{clients: map[string]model.APIClient{
+		"reader-key":   {ID: 1, Scopes: []string{model.ScopeRead}},
+		"searcher-key": {ID: 2, Scopes: []string{model.ScopeSearch}},
+	}}
+	_, client := startServer(t, servicetest.NewFakeVideoService(1), apiClientService)
+	withKey := func(key string, value string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), key, value)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		code codes.Code
+	}{
+		{"missing key", context.Background(), listVideos(client), codes.Unauthenticated},
+		{"unknown key", withKey("x-api-key", "unknown"), listVideos(client), codes.Unauthenticated},
+		{"read scope", withKey("authorization", "Bearer reader-key"), listVideos(client), codes.OK},
+		{"missing search scope", withKey("x-api-key", "reader-key"), searchVideos(client), codes.PermissionDenied},
+		{"search scope", withKey("authorization", "bearer searcher-key"), searchVideos(client), codes.OK},
+		{"missing read scope", withKey("x-api-key", "searcher-key"), listVideos(client), codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		if code := status.Code(test.call(test.ctx)); code != test.code {
+			t.Errorf("%s: code = %v, want %v", test.name, code, test.code)
+		}
+	}
+}
+
+func listVideos(client videopb.VideoServiceClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.ListVideos(ctx, &videopb.ListVideosRequest{})
+		return err
+	}
+}
+
+func searchVideos(client videopb.VideoServiceClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.SearchVideos(ctx, &videopb.SearchVideosRequest{Query: "go"})
+		return err
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	config.Conf.RateLimit = config.RateLimit{Enabled: true, VideosPerMinute: 1, VideosBurst: 1, SearchPerMinute: 1, SearchBurst: 1}
+	defer func() { config.Conf.RateLimit = config.RateLimit{} }()
+	_, client := startServer(t, servicetest.NewFakeVideoService(1), fakeAPIClientService{})
+	ctx := context.Background()
+
+	if err := listVideos(client)(ctx); err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	// the search has its own budget
+	if err := searchVideos(client)(ctx); err != nil {
+		t.Fatalf("SearchVideos() error = %v", err)
+	}
+	for name, call := range map[string]func(ctx context.Context) error{"ListVideos": listVideos(client), "SearchVideos": searchVideos(client)} {
+		if code, reason := errorReason(call(ctx)); code != codes.ResourceExhausted || reason != "rate_limited" {
+			t.Errorf("%s() error code = %v %s, want ResourceExhausted rate_limited", name, code, reason)
+		}
+	}
+}
+
+func TestWatchNewVideosEndsOnShutdown(t *testing.T) {
+	videoService := servicetest.NewFakeVideoService(1)
+	server, client := startServer(t, videoService, fakeAPIClientService{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	negativeID := int64(-1)
+	invalid, _ := client.WatchNewVideos(ctx, &videopb.WatchNewVideosRequest{AfterId: &negativeID})
+	if _, err := invalid.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Recv() error = %v, want InvalidArgument for a negative after id", err)
+	}
+
+	stream, err := client.WatchNewVideos(ctx, &videopb.WatchNewVideosRequest{Query: "golang"})
+	if err != nil {
+		t.Fatalf("WatchNewVideos() error = %v", err)
+	}
+	videoService.Watched <- videoService.Videos[0]
+	video, err := stream.Recv()
+	if err != nil || video.YoutubeId != "v0" {
+		t.Fatalf("Recv() = %v, %v, want the video v0", video, err)
+	}
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() error = %v, want the streams to end before the timeout", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv() error = %v, want Unavailable once the server shuts down", err)
+	}
+}
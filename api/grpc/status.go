@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"net/http"
+)
+
+// errorDomain is the domain of the error infos of the status details
+const errorDomain = "youtube-search-api"
+
+// statusCodes are the gRPC status codes of the HTTP status codes of the custom errors
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// statusError returns the status error of the custom error of er.ToError. The stable code of the error is the reason
+// of the ErrorInfo detail of the status, and the invalid field of the validation errors is in its BadRequest detail.
+func statusError(ctx context.Context, err error) error {
+	customErr := er.ToError(ctx, err)
+	code, ok := statusCodes[customErr.HttpStatusCode]
+	if !ok {
+		code = codes.Unknown
+	}
+	details := []protoiface.MessageV1{&errdetails.ErrorInfo{Reason: customErr.Code, Domain: errorDomain}}
+	if customErr.Field != "" {
+		details = append(details, &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: customErr.Field, Description: customErr.ErrMessage},
+		}})
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: requestID})
+	}
+	st, detailsErr := status.New(code, customErr.ErrMessage).WithDetails(details...)
+	if detailsErr != nil {
+		return status.Error(code, customErr.ErrMessage)
+	}
+	return st.Err()
+}
@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/grpc/videopb"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+)
+
+// videoServer serves the video service with the same validation as the REST routes
+type videoServer struct {
+	videopb.UnimplementedVideoServiceServer
+	videoService service.VideoService
+	stopping     <-chan struct{}
+}
+
+func newVideoServer(videoService service.VideoService, stopping <-chan struct{}) videopb.VideoServiceServer {
+	return videoServer{
+		videoService: videoService,
+		stopping:     stopping,
+	}
+}
+
+// ListVideos returns the videos in reverse chronological order of their publishing date
+func (v videoServer) ListVideos(ctx context.Context, request *videopb.ListVideosRequest) (*videopb.ListVideosResponse, error) {
+	limit, offset, err := page(request.Limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+	videos, err := v.videoService.GetVideos(ctx, model.GetVideosRequest{Limit: limit, Offset: offset, Collapse: request.Collapse})
+	if err != nil {
+		return nil, err
+	}
+	return &videopb.ListVideosResponse{Videos: toVideos(videos)}, nil
+}
+
+// GetVideo returns the video of the YouTube id
+func (v videoServer) GetVideo(ctx context.Context, request *videopb.GetVideoRequest) (*videopb.Video, error) {
+	if request.YoutubeId == "" {
+		return nil, er.ErrYoutubeIDRequired
+	}
+	video, err := v.videoService.GetVideo(ctx, request.YoutubeId)
+	if err != nil {
+		return nil, err
+	}
+	return toVideo(video), nil
+}
+
+// SearchVideos returns the videos matching the query ranked by relevance
+func (v videoServer) SearchVideos(ctx context.Context, request *videopb.SearchVideosRequest) (*videopb.SearchVideosResponse, error) {
+	searchRequest := model.SearchVideosRequest{
+		SearchString: strings.TrimSpace(request.Query),
+		Fuzzy:        request.Fuzzy,
+		Language:     request.Language,
+		Facets:       request.Facets,
+		Collapse:     request.Collapse,
+	}
+	if searchRequest.SearchString == "" {
+		return nil, er.ErrSearchQueryRequired
+	}
+	var err error
+	searchRequest.Limit, searchRequest.Offset, err = page(request.Limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if request.RankingProfile != "" {
+		searchRequest.Ranking = &model.RankingOptions{Profile: request.RankingProfile}
+	}
+	searchResponse, err := v.videoService.SearchVideos(ctx, searchRequest)
+	if err != nil {
+		return nil, err
+	}
+	response := &videopb.SearchVideosResponse{
+		Videos:     toVideos(searchResponse.Videos),
+		DidYouMean: searchResponse.DidYouMean,
+	}
+	if len(searchResponse.Facets) > 0 {
+		response.Facets = make(map[string]*videopb.FacetBuckets, len(searchResponse.Facets))
+		for facet, buckets := range searchResponse.Facets {
+			facetBuckets := &videopb.FacetBuckets{}
+			for _, bucket := range buckets {
+				facetBuckets.Buckets = append(facetBuckets.Buckets, &videopb.FacetBucket{Value: bucket.Value, Label: bucket.Label, Count: int32(bucket.Count)})
+			}
+			response.Facets[facet] = facetBuckets
+		}
+	}
+	return response, nil
+}
+
+// WatchNewVideos streams the newly inserted videos matching the optional query and filters, resuming after the
+// video of the after id when it is set. The stream ends with UNAVAILABLE when watching is interrupted or the server
+// is shutting down, so that the client resumes from the last received video.
+func (v videoServer) WatchNewVideos(request *videopb.WatchNewVideosRequest, stream videopb.VideoService_WatchNewVideosServer) error {
+	watchRequest := model.WatchVideosRequest{
+		AfterID: request.AfterId,
+		Query:   request.Query,
+		Filters: model.VideoFilters{
+			Language:  request.Language,
+			ChannelID: request.ChannelId,
+			Keyword:   request.Keyword,
+		},
+	}
+	if watchRequest.AfterID != nil && *watchRequest.AfterID < 0 {
+		return er.ErrInvalidAfterID
+	}
+	// the videos stop being watched once the stream ends
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	videos, err := v.videoService.WatchNewVideos(ctx, watchRequest)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case video, ok := <-videos:
+			if !ok {
+				if err := stream.Context().Err(); err != nil {
+					return status.FromContextError(err).Err()
+				}
+				return status.Error(codes.Unavailable, "watching new videos was interrupted, resume after the last received video")
+			}
+			if err := stream.Send(toVideo(video)); err != nil {
+				return err
+			}
+		case <-v.stopping:
+			return status.Error(codes.Unavailable, "server is shutting down, resume after the last received video")
+		}
+	}
+}
+
+// page returns the limit and offset of a page of videos, the limit is service.DefaultPageSize when it isn't set
+func page(limit int32, offset int32) (int, int, error) {
+	if limit == 0 {
+		limit = service.DefaultPageSize
+	}
+	if err := service.ValidatePage(int(limit), int(offset)); err != nil {
+		return 0, 0, err
+	}
+	return int(limit), int(offset), nil
+}
+
+func toVideos(videos []model.VideoMetadata) []*videopb.Video {
+	messages := make([]*videopb.Video, 0, len(videos))
+	for _, video := range videos {
+		messages = append(messages, toVideo(video))
+	}
+	return messages
+}
+
+func toVideo(video model.VideoMetadata) *videopb.Video {
+	return &videopb.Video{
+		Id:              video.ID,
+		YoutubeId:       video.YoutubeID,
+		Title:           video.Title,
+		Description:     video.Description,
+		PublishedAt:     timestamppb.New(video.PublishedAt),
+		ThumbnailUrl:    video.ThumbnailURL,
+		Language:        video.Language,
+		ChannelId:       video.ChannelID,
+		ChannelTitle:    video.ChannelTitle,
+		DurationSeconds: int32Pointer(video.DurationSeconds),
+		Keyword:         video.Keyword,
+		ViewCount:       video.ViewCount,
+		Duplicates:      int32Pointer(video.Duplicates),
+	}
+}
+
+func int32Pointer(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
@@ -0,0 +1,5 @@
+// Package videopb has the protobuf messages and the gRPC service of the videos, generated from video.proto with
+// protoc-gen-go v1.28.1 and protoc-gen-go-grpc v1.2.0
+package videopb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative video.proto
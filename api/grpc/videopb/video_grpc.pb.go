@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: video.proto
+
+package videopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// VideoServiceClient is the client API for VideoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VideoServiceClient interface {
+	// ListVideos returns the videos in reverse chronological order of their publishing date, requires the read scope
+	ListVideos(ctx context.Context, in *ListVideosRequest, opts ...grpc.CallOption) (*ListVideosResponse, error)
+	// GetVideo returns the video of the YouTube id, requires the read scope
+	GetVideo(ctx context.Context, in *GetVideoRequest, opts ...grpc.CallOption) (*Video, error)
+	// SearchVideos returns the videos matching the query ranked by relevance, requires the search scope
+	SearchVideos(ctx context.Context, in *SearchVideosRequest, opts ...grpc.CallOption) (*SearchVideosResponse, error)
+	// WatchNewVideos streams the videos inserted from now on, or after the video of after_id, which match the optional
+	// query and filters, requires the read scope. The stream ends with UNAVAILABLE when watching is interrupted, after
+	// which it can be resumed from the id of the last received video.
+	WatchNewVideos(ctx context.Context, in *WatchNewVideosRequest, opts ...grpc.CallOption) (VideoService_WatchNewVideosClient, error)
+}
+
+type videoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVideoServiceClient(cc grpc.ClientConnInterface) VideoServiceClient {
+	return &videoServiceClient{cc}
+}
+
+func (c *videoServiceClient) ListVideos(ctx context.Context, in *ListVideosRequest, opts ...grpc.CallOption) (*ListVideosResponse, error) {
+	out := new(ListVideosResponse)
+	err := c.cc.Invoke(ctx, "/youtubesearch.v1.VideoService/ListVideos", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *videoServiceClient) GetVideo(ctx context.Context, in *GetVideoRequest, opts ...grpc.CallOption) (*Video, error) {
+	out := new(Video)
+	err := c.cc.Invoke(ctx, "/youtubesearch.v1.VideoService/GetVideo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *videoServiceClient) SearchVideos(ctx context.Context, in *SearchVideosRequest, opts ...grpc.CallOption) (*SearchVideosResponse, error) {
+	out := new(SearchVideosResponse)
+	err := c.cc.Invoke(ctx, "/youtubesearch.v1.VideoService/SearchVideos", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *videoServiceClient) WatchNewVideos(ctx context.Context, in *WatchNewVideosRequest, opts ...grpc.CallOption) (VideoService_WatchNewVideosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VideoService_ServiceDesc.Streams[0], "/youtubesearch.v1.VideoService/WatchNewVideos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &videoServiceWatchNewVideosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VideoService_WatchNewVideosClient interface {
+	Recv() (*Video, error)
+	grpc.ClientStream
+}
+
+type videoServiceWatchNewVideosClient struct {
+	grpc.ClientStream
+}
+
+func (x *videoServiceWatchNewVideosClient) Recv() (*Video, error) {
+	m := new(Video)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VideoServiceServer is the server API for VideoService service.
+// All implementations must embed UnimplementedVideoServiceServer
+// for forward compatibility
+type VideoServiceServer interface {
+	// ListVideos returns the videos in reverse chronological order of their publishing date, requires the read scope
+	ListVideos(context.Context, *ListVideosRequest) (*ListVideosResponse, error)
+	// GetVideo returns the video of the YouTube id, requires the read scope
+	GetVideo(context.Context, *GetVideoRequest) (*Video, error)
+	// SearchVideos returns the videos matching the query ranked by relevance, requires the search scope
+	SearchVideos(context.Context, *SearchVideosRequest) (*SearchVideosResponse, error)
+	// WatchNewVideos streams the videos inserted from now on, or after the video of after_id, which match the optional
+	// query and filters, requires the read scope. The stream ends with UNAVAILABLE when watching is interrupted, after
+	// which it can be resumed from the id of the last received video.
+	WatchNewVideos(*WatchNewVideosRequest, VideoService_WatchNewVideosServer) error
+	mustEmbedUnimplementedVideoServiceServer()
+}
+
+// UnimplementedVideoServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedVideoServiceServer struct {
+}
+
+func (UnimplementedVideoServiceServer) ListVideos(context.Context, *ListVideosRequest) (*ListVideosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVideos not implemented")
+}
+func (UnimplementedVideoServiceServer) GetVideo(context.Context, *GetVideoRequest) (*Video, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVideo not implemented")
+}
+func (UnimplementedVideoServiceServer) SearchVideos(context.Context, *SearchVideosRequest) (*SearchVideosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchVideos not implemented")
+}
+func (UnimplementedVideoServiceServer) WatchNewVideos(*WatchNewVideosRequest, VideoService_WatchNewVideosServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchNewVideos not implemented")
+}
+func (UnimplementedVideoServiceServer) mustEmbedUnimplementedVideoServiceServer() {}
+
+// UnsafeVideoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VideoServiceServer will
+// result in compilation errors.
+type UnsafeVideoServiceServer interface {
+	mustEmbedUnimplementedVideoServiceServer()
+}
+
+func RegisterVideoServiceServer(s grpc.ServiceRegistrar, srv VideoServiceServer) {
+	s.RegisterService(&VideoService_ServiceDesc, srv)
+}
+
+func _VideoService_ListVideos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVideosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VideoServiceServer).ListVideos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/youtubesearch.v1.VideoService/ListVideos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VideoServiceServer).ListVideos(ctx, req.(*ListVideosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VideoService_GetVideo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVideoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VideoServiceServer).GetVideo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/youtubesearch.v1.VideoService/GetVideo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VideoServiceServer).GetVideo(ctx, req.(*GetVideoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VideoService_SearchVideos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchVideosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VideoServiceServer).SearchVideos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/youtubesearch.v1.VideoService/SearchVideos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VideoServiceServer).SearchVideos(ctx, req.(*SearchVideosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VideoService_WatchNewVideos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNewVideosRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VideoServiceServer).WatchNewVideos(m, &videoServiceWatchNewVideosServer{stream})
+}
+
+type VideoService_WatchNewVideosServer interface {
+	Send(*Video) error
+	grpc.ServerStream
+}
+
+type videoServiceWatchNewVideosServer struct {
+	grpc.ServerStream
+}
+
+func (x *videoServiceWatchNewVideosServer) Send(m *Video) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// VideoService_ServiceDesc is the grpc.ServiceDesc for VideoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VideoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "youtubesearch.v1.VideoService",
+	HandlerType: (*VideoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListVideos",
+			Handler:    _VideoService_ListVideos_Handler,
+		},
+		{
+			MethodName: "GetVideo",
+			Handler:    _VideoService_GetVideo_Handler,
+		},
+		{
+			MethodName: "SearchVideos",
+			Handler:    _VideoService_SearchVideos_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchNewVideos",
+			Handler:       _VideoService_WatchNewVideos_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "video.proto",
+}
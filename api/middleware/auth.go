@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// apiClientKey is the key of the authenticated client in the gin context
+const apiClientKey = "apiClient"
+
+// Authenticate returns a middleware which authenticates the client by the API key of the Authorization: Bearer or
+// X-API-Key header, and aborts the requests of the clients which don't have the given scope.
+// All the requests are allowed when the authentication is disabled.
+func Authenticate(apiClientService service.APIClientService, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.Conf.Auth.Enabled {
+			c.Next()
+			return
+		}
+		key := requestAPIKey(c)
+		if key == "" {
+			abortUnauthorized(c, er.ErrUnauthorized)
+			return
+		}
+		client, err := apiClientService.Authenticate(c.Request.Context(), key)
+		if err == er.ErrUnauthorized {
+			abortUnauthorized(c, err)
+			return
+		}
+		if err != nil {
+			er.SendError(c, err)
+			c.Abort()
+			return
+		}
+		if !client.HasScope(scope) {
+			er.SendError(c, er.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Set(apiClientKey, client)
+		c.Next()
+	}
+}
+
+// GetAPIClient returns the client authenticated by Authenticate, false is returned when the authentication is disabled
+func GetAPIClient(c *gin.Context) (model.APIClient, bool) {
+	value, ok := c.Get(apiClientKey)
+	if !ok {
+		return model.APIClient{}, false
+	}
+	client, ok := value.(model.APIClient)
+	return client, ok
+}
+
+// requestAPIKey returns the API key of the request, the Authorization header is preferred over the X-API-Key header
+func requestAPIKey(c *gin.Context) string {
+	authorization := c.GetHeader("Authorization")
+	if scheme, token, ok := strings.Cut(authorization, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
+}
+
+func abortUnauthorized(c *gin.Context, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="youtube-search-api"`)
+	er.SendError(c, err)
+	c.Abort()
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeAPIClientService authenticates the clients of the keys
+type fakeAPIClientService struct {
+	service.APIClientService
+	clients map[string]model.APIClient
+}
+
+func (f fakeAPIClientService) Authenticate(_ context.Context, key string) (model.APIClient, error) {
+	client, ok := f.clients[key]
+	if !ok {
+		return client, er.ErrUnauthorized
+	}
+	return client, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Conf.Auth.Enabled = true
+	defer func() { config.Conf.Auth.Enabled = false }()
+	apiClientService := fakeAPIClientService{clients: map[string]model.APIClient{
+		"reader-key": {ID: 1, Scopes: []string{model.ScopeRead}},
+		"admin-key":  {ID: 2, Scopes: []string{model.ScopeAdmin}},
+	}}
+	router := gin.New()
+	router.GET("/videos", Authenticate(apiClientService, model.ScopeRead), func(c *gin.Context) {
+		client, _ := GetAPIClient(c)
+		c.JSON(http.StatusOK, client.ID)
+	})
+	router.GET("/admin", Authenticate(apiClientService, model.ScopeAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		value      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"no key", "/videos", "", "", http.StatusUnauthorized, ""},
+		{"unknown key", "/videos", "X-API-Key", "unknown", http.StatusUnauthorized, ""},
+		{"bearer key", "/videos", "Authorization", "Bearer reader-key", http.StatusOK, "1"},
+		{"lower case bearer scheme", "/videos", "Authorization", "bearer reader-key", http.StatusOK, "1"},
+		{"x-api-key", "/videos", "X-API-Key", "reader-key", http.StatusOK, "1"},
+		{"basic scheme", "/videos", "Authorization", "Basic reader-key", http.StatusUnauthorized, ""},
+		{"missing scope", "/admin", "X-API-Key", "reader-key", http.StatusForbidden, ""},
+		{"admin has all scopes", "/videos", "X-API-Key", "admin-key", http.StatusOK, "2"},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.header != "" {
+			request.Header.Set(tt.header, tt.value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, tt.wantStatus)
+		}
+		if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+			t.Errorf("%s: body = %s, want %s", tt.name, recorder.Body.String(), tt.wantBody)
+		}
+		if tt.wantStatus == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: WWW-Authenticate header is missing", tt.name)
+		}
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Conf.Auth.Enabled = false
+	router := gin.New()
+	router.GET("/admin", Authenticate(fakeAPIClientService{}, model.ScopeAdmin), func(c *gin.Context) {
+		if _, ok := GetAPIClient(c); ok {
+			t.Errorf("GetAPIClient() ok = true when the authentication is disabled")
+		}
+		c.Status(http.StatusOK)
+	})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Logger returns a middleware which logs each request once it is handled, with its request id. The server errors are
+// logged at the error level and the client errors at the warn level.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		attributes := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			attributes = append(attributes, "errors", c.Errors.String())
+		}
+		slog.Log(c.Request.Context(), level, "request", attributes...)
+	}
+}
+
+// Recovery returns a middleware which recovers from the panics of the handlers, logs them with their stack trace and
+// responds with the internal error
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				slog.ErrorContext(c.Request.Context(), "panic handling request", "err", err, "stack", string(debug.Stack()))
+				er.SendError(c, er.ErrInternal)
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
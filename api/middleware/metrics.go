@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/Gohelraj/youtube-search-api/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// Metrics returns a middleware which records the latency of the requests by method, route and status code.
+// The requests not matching any route are recorded under the "unmatched" route.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
+}
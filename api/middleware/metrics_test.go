@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/Gohelraj/youtube-search-api/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/videos/:youtubeId/similar", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	for _, path := range []string{"/videos/abc123/similar", "/videos/def456/similar", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// the requests are recorded by route pattern rather than by path
+	tests := []struct {
+		route  string
+		status string
+		want   uint64
+	}{
+		{"/videos/:youtubeId/similar", "200", 2},
+		{"unmatched", "404", 1},
+	}
+	for _, tt := range tests {
+		var metric dto.Metric
+		if err := metrics.HTTPRequestDuration.WithLabelValues(http.MethodGet, tt.route, tt.status).(prometheus.Metric).Write(&metric); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if got := metric.GetHistogram().GetSampleCount(); got != tt.want {
+			t.Errorf("recorded %d requests of %s with status %s, want %d", got, tt.route, tt.status, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Budgets of the rate limits, the expensive searches have a separate budget so that searching doesn't starve listing
+// the videos
+const (
+	VideosBudget = "videos"
+	SearchBudget = "search"
+)
+
+// BudgetLimit returns the configured limit of the budget
+func BudgetLimit(budget string) ratelimit.Limit {
+	if budget == SearchBudget {
+		return ratelimit.Limit{PerMinute: config.Conf.RateLimit.SearchPerMinute, Burst: config.Conf.RateLimit.SearchBurst}
+	}
+	return ratelimit.Limit{PerMinute: config.Conf.RateLimit.VideosPerMinute, Burst: config.Conf.RateLimit.VideosBurst}
+}
+
+// RateLimitKeys returns the keys of the buckets of the budget of the client IP and of the API client of a request,
+// which are the same for all the APIs. The client is nil when the request isn't authenticated.
+func RateLimitKeys(budget string, clientIP string, client *model.APIClient) []string {
+	keys := []string{fmt.Sprintf("%s:ip:%s", budget, clientIP)}
+	if client != nil {
+		keys = append(keys, fmt.Sprintf("%s:client:%d", budget, client.ID))
+	}
+	return keys
+}
+
+// RateLimit returns a middleware which limits the requests of the budget of each API client and each IP with TakeRateLimit.
+// It must run after Authenticate.
+func RateLimit(limiter ratelimit.Limiter, budget string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := TakeRateLimit(c, limiter, budget, limit); err != nil {
+			er.SendError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// TakeRateLimit takes a token of the budget of the API client and of the IP of the request, and sets the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers of the most restrictive of their buckets.
+// er.ErrRateLimited is returned with the Retry-After header when the request is over the limit. The requests are
+// allowed when the limiter fails, so that its store is not a single point of failure.
+func TakeRateLimit(c *gin.Context, limiter ratelimit.Limiter, budget string, limit ratelimit.Limit) error {
+	if !config.Conf.RateLimit.Enabled {
+		return nil
+	}
+	var client *model.APIClient
+	if authenticated, ok := GetAPIClient(c); ok {
+		client = &authenticated
+	}
+	limited, ok := ratelimit.TakeAll(c.Request.Context(), limiter, RateLimitKeys(budget, c.ClientIP(), client), limit)
+	if !ok {
+		return nil
+	}
+	c.Header("RateLimit-Limit", strconv.Itoa(limited.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(limited.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(limited.Reset)))
+	if !limited.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(limited.RetryAfter)))
+		return er.ErrRateLimited
+	}
+	return nil
+}
+
+// ceilSeconds rounds the duration up to whole seconds, so that clients don't retry before the next token
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
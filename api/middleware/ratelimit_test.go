@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"github.com/Gohelraj/youtube-search-api/config"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Conf.RateLimit.Enabled = true
+	defer func() { config.Conf.RateLimit.Enabled = false }()
+	router := gin.New()
+	// 1 token per minute, up to 2 tokens
+	router.GET("/videos", RateLimit(ratelimit.NewMemoryLimiter(), "videos", ratelimit.Limit{PerMinute: 1, Burst: 2}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/videos", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	for _, wantRemaining := range []string{"1", "0"} {
+		recorder := get("192.0.2.1:1234")
+		if recorder.Code != http.StatusOK || recorder.Header().Get("RateLimit-Remaining") != wantRemaining || recorder.Header().Get("RateLimit-Limit") != "2" {
+			t.Fatalf("status = %d, headers = %v, want 200 with %s remaining", recorder.Code, recorder.Header(), wantRemaining)
+		}
+	}
+	recorder := get("192.0.2.1:1234")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusTooManyRequests)
+	}
+	if got := recorder.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+	if got := recorder.Header().Get("RateLimit-Reset"); got != "120" {
+		t.Errorf("RateLimit-Reset = %q, want %q", got, "120")
+	}
+	// each IP has its own bucket
+	if recorder = get("192.0.2.2:1234"); recorder.Code != http.StatusOK {
+		t.Errorf("status of another IP = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header carrying the id of a request
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximum length of the request ids accepted from the clients
+const maxRequestIDLength = 128
+
+// RequestID returns a middleware which sets the id of the request in the X-Request-ID response header and in the
+// context of the request, so that the logs of the request carry it. The id sent by the client or a proxy in the
+// X-Request-ID header is kept when it is valid, otherwise a random id is generated.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !IsValidRequestID(requestID) {
+			requestID = NewRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// IsValidRequestID reports whether the request id is short and only has characters which are safe to log
+func IsValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRequestID returns a random request id
+func NewRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
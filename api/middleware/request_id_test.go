@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var contextID string
+	router.GET("/videos", RequestID(), func(c *gin.Context) {
+		contextID = logging.RequestID(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"generated", "", false},
+		{"kept", "edge-7f3a.1_b", true},
+		{"unsafe characters", "id\nforged=log", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/videos", nil)
+		if tt.header != "" {
+			request.Header.Set(RequestIDHeader, tt.header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		got := recorder.Header().Get(RequestIDHeader)
+		if got == "" || got != contextID {
+			t.Errorf("%s: response id = %q and context id = %q, want the same id", tt.name, got, contextID)
+		}
+		if (got == tt.header) != tt.keep {
+			t.Errorf("%s: response id = %q, want the sent id kept = %v", tt.name, got, tt.keep)
+		}
+	}
+}
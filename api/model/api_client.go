@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Scopes of the API clients, the admin scope grants all the other scopes too
+const (
+	ScopeRead   = "read"
+	ScopeSearch = "search"
+	ScopeAdmin  = "admin"
+)
+
+// APIClient client of the API authenticated by its key. Only the hash of the key is stored, so the key is returned
+// once when the client is created or its key is rotated. KeyPrefix is the start of the key which identifies it.
+type APIClient struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Key        string     `json:"key,omitempty"`
+	KeyPrefix  string     `json:"keyPrefix"`
+	KeyHash    string     `json:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// HasScope returns true if the client is granted the scope
+func (c APIClient) HasScope(scope string) bool {
+	for _, granted := range c.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// SavedSearch search saved by an analyst, which is evaluated against the newly inserted videos to record its new matches
+type SavedSearch struct {
+	ID        int64        `json:"id,omitempty"`
+	Name      string       `json:"name"`
+	Query     string       `json:"query"`
+	Filters   VideoFilters `json:"filters"`
+	Owner     string       `json:"owner"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
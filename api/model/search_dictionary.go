@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// SearchSynonym synonyms of a search term, a search term matches videos containing any of its synonyms
+type SearchSynonym struct {
+	ID        int64     `json:"id,omitempty"`
+	Term      string    `json:"term"`
+	Synonyms  []string  `json:"synonyms"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SearchStopword domain specific stopword which is neither indexed nor searched
+type SearchStopword struct {
+	Word      string    `json:"word"`
+	CreatedAt time.Time `json:"createdAt"`
+}
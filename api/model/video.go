@@ -1,20 +1,141 @@
 package model
 
 import (
+	"github.com/Gohelraj/youtube-search-api/config"
 	"time"
 )
 
-// VideoMetadata video's metadata
+// VideoMetadata video's metadata, Keyword is the search keyword the video was fetched from YouTube for.
+// Duplicates is the number of near-duplicates of the video, returned only when the near-duplicates are collapsed.
+// Fingerprint (SimHash of the title and description) and DuplicateOf (YouTube id of a video the video is
+// a near-duplicate of) are set by the ingestion consumer to cluster the near-duplicates.
 type VideoMetadata struct {
-	ID           int64     `json:"id,omitempty"`
-	YoutubeID    string    `json:"youtubeId"`
-	Title        string    `json:"title"`
-	Description  *string   `json:"description,omitempty"`
-	PublishedAt  time.Time `json:"publishedAt"`
-	ThumbnailURL *string   `json:"thumbnailUrl,omitempty"`
+	ID              int64           `json:"id,omitempty"`
+	YoutubeID       string          `json:"youtubeId"`
+	Title           string          `json:"title"`
+	Description     *string         `json:"description,omitempty"`
+	PublishedAt     time.Time       `json:"publishedAt"`
+	ThumbnailURL    *string         `json:"thumbnailUrl,omitempty"`
+	Language        *string         `json:"language,omitempty"`
+	ChannelID       *string         `json:"channelId,omitempty"`
+	ChannelTitle    *string         `json:"channelTitle,omitempty"`
+	DurationSeconds *int            `json:"durationSeconds,omitempty"`
+	Keyword         *string         `json:"keyword,omitempty"`
+	ViewCount       *int64          `json:"viewCount,omitempty"`
+	ClusterID       *int64          `json:"clusterId,omitempty"`
+	Duplicates      *int            `json:"duplicates,omitempty"`
+	Fingerprint     *int64          `json:"-"`
+	DuplicateOf     *string         `json:"-"`
+	CreatedAt       time.Time       `json:"-"`
+	Highlight       *VideoHighlight `json:"highlight,omitempty"`
+	Score           *SearchScore    `json:"score,omitempty"`
+}
+
+// SearchScore relevance score of a search hit and the factors it is computed from
+type SearchScore struct {
+	Score            float64 `json:"score"`
+	TextRank         float64 `json:"textRank"`
+	RecencyFactor    float64 `json:"recencyFactor"`
+	PopularityFactor float64 `json:"popularityFactor"`
+}
+
+// VideoHighlight highlighted fragments of video's title and description which matched the search string
+type VideoHighlight struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+}
+
+// GetVideosRequest get videos request, when Collapse is true only the first published video of each cluster of near-duplicates is returned
+type GetVideosRequest struct {
+	Limit    int
+	Offset   int
+	Collapse bool
+}
+
+// VideoFingerprint SimHash fingerprint of a stored video
+type VideoFingerprint struct {
+	YoutubeID   string
+	Fingerprint int64
 }
 
 // SearchVideosRequest search videos request
 type SearchVideosRequest struct {
-	SearchString string `json:"searchString"`
+	SearchString string            `json:"searchString"`
+	Limit        int               `json:"limit"`
+	Offset       int               `json:"offset"`
+	Highlight    *HighlightOptions `json:"highlight,omitempty"`
+	Fuzzy        bool              `json:"fuzzy"`
+	Language     string            `json:"language,omitempty"`
+	Facets       []string          `json:"facets,omitempty"`
+	Ranking      *RankingOptions   `json:"ranking,omitempty"`
+	Explain      bool              `json:"explain"`
+	Collapse     bool              `json:"collapse"`
+}
+
+// Text rank functions of Postgres which can be used for ranking search results
+const (
+	RankFunctionTsRank   = "ts_rank"
+	RankFunctionTsRankCd = "ts_rank_cd"
+)
+
+// RankingOptions scoring model of search results, the options of the ranking profile of the given name overridden by
+// the options which are set
+type RankingOptions struct {
+	Profile string `json:"profile,omitempty"`
+	config.RankingProfile
+}
+
+// SearchVideosResponse search videos response
+type SearchVideosResponse struct {
+	Videos     []VideoMetadata `json:"videos"`
+	DidYouMean *string         `json:"didYouMean,omitempty"`
+	// Facets buckets of the requested facets over all the matching videos, keyed by facet
+	Facets map[string][]FacetBucket `json:"facets,omitempty"`
+}
+
+// Search facets by which the matching videos can be aggregated
+const (
+	FacetChannel      = "channel"
+	FacetPublishMonth = "publishMonth"
+	FacetDuration     = "duration"
+	FacetKeyword      = "keyword"
+)
+
+// SearchFacets all the supported search facets
+var SearchFacets = []string{FacetChannel, FacetPublishMonth, FacetDuration, FacetKeyword}
+
+// FacetBucket number of matching videos having the value of a facet
+type FacetBucket struct {
+	Value string  `json:"value"`
+	Label *string `json:"label,omitempty"`
+	Count int     `json:"count"`
+}
+
+// HighlightOptions options used to generate highlighted fragments of the search results
+type HighlightOptions struct {
+	StartSel     string `json:"startSel"`
+	StopSel      string `json:"stopSel"`
+	MaxFragments int    `json:"maxFragments"`
+}
+
+// VideoSuggestions autocomplete suggestions for a partially typed search string
+type VideoSuggestions struct {
+	Titles  []string `json:"titles"`
+	Queries []string `json:"queries"`
+}
+
+// VideoFilters filters of the videos matching a saved search or a webhook, unset filters match all the videos
+type VideoFilters struct {
+	// Language of the query, which is stemmed using its text search configuration
+	Language  *string `json:"language,omitempty"`
+	ChannelID *string `json:"channelId,omitempty"`
+	Keyword   *string `json:"keyword,omitempty"`
+}
+
+// WatchVideosRequest request to watch the newly inserted videos matching the optional query and filters,
+// AfterID is the id of the last received video to resume watching from
+type WatchVideosRequest struct {
+	AfterID *int64
+	Query   string
+	Filters VideoFilters
 }
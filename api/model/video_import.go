@@ -0,0 +1,20 @@
+package model
+
+// VideoImportResult result of a bulk import of videos. Skipped is the number of the valid videos which were already
+// stored, Failed is the number of the invalid rows, and Enriched is the number of the rows imported with their
+// YouTube id only whose metadata was fetched from YouTube. Only the first errors of the rows are returned.
+type VideoImportResult struct {
+	Rows     int                `json:"rows"`
+	Inserted int                `json:"inserted"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Enriched int                `json:"enriched"`
+	Errors   []VideoImportError `json:"errors"`
+}
+
+// VideoImportError error of a row of an imported file, Line is the line number of the row in the file
+type VideoImportError struct {
+	Line      int    `json:"line"`
+	YoutubeID string `json:"youtubeId,omitempty"`
+	Error     string `json:"error"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventVideosInserted is the event of the videos newly inserted by an ingestion batch
+const WebhookEventVideosInserted = "videos.inserted"
+
+// Statuses of a webhook delivery, a pending delivery is attempted until it succeeds or runs out of attempts
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook subscription of a downstream system to the newly inserted videos, matching the optional query and filters.
+// Payloads are signed with the secret, which is never returned.
+type Webhook struct {
+	ID        int64        `json:"id,omitempty"`
+	URL       string       `json:"url"`
+	Secret    string       `json:"secret,omitempty"`
+	Query     *string      `json:"query,omitempty"`
+	Filters   VideoFilters `json:"filters"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// WebhookPayload JSON payload delivered to a webhook
+type WebhookPayload struct {
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Videos    []VideoMetadata `json:"videos"`
+}
+
+// WebhookDelivery delivery of a payload to a webhook, Payload and Attempts are returned only for a single delivery
+type WebhookDelivery struct {
+	ID             int64                    `json:"id"`
+	WebhookID      int64                    `json:"webhookId"`
+	Event          string                   `json:"event"`
+	Status         string                   `json:"status"`
+	AttemptCount   int                      `json:"attemptCount"`
+	NextAttemptAt  *time.Time               `json:"nextAttemptAt,omitempty"`
+	LastStatusCode *int                     `json:"lastStatusCode,omitempty"`
+	LastError      *string                  `json:"lastError,omitempty"`
+	CreatedAt      time.Time                `json:"createdAt"`
+	DeliveredAt    *time.Time               `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage          `json:"payload,omitempty"`
+	Attempts       []WebhookDeliveryAttempt `json:"attempts,omitempty"`
+}
+
+// WebhookDeliveryAttempt attempt to deliver a payload, StatusCode is not set if the request failed before a response
+type WebhookDeliveryAttempt struct {
+	AttemptedAt time.Time `json:"attemptedAt"`
+	StatusCode  *int      `json:"statusCode,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+}
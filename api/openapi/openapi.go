@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Spec is the OpenAPI 3 document describing all the routes of the API. It is maintained by hand along with the
+// routes, and TestSpecMatchesRoutes fails when they diverge.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// swaggerUI is the page of the Swagger UI rendering the spec, its assets are loaded from the jsDelivr CDN
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>YouTube Search API</title>
+  <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// GetSpec returns the OpenAPI document
+func GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", Spec)
+}
+
+// GetDocs returns the Swagger UI page of the OpenAPI document
+func GetDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI))
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type APIClientRepository interface {
+	GetAPIClients(ctx context.Context) ([]model.APIClient, error)
+	GetAPIClient(ctx context.Context, id int64) (model.APIClient, error)
+	GetActiveAPIClientByKeyHash(ctx context.Context, keyHash string) (model.APIClient, error)
+	InsertAPIClient(ctx context.Context, client model.APIClient) (model.APIClient, error)
+	RotateAPIClientKey(ctx context.Context, id int64, keyPrefix string, keyHash string) (model.APIClient, error)
+	RevokeAPIClient(ctx context.Context, id int64) (model.APIClient, error)
+	TouchAPIClient(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+// apiClientColumns are the columns of api_clients table scanned by apiClientDest
+const apiClientColumns = "id, name, scopes, key_prefix, key_hash, created_at, updated_at, last_used_at, revoked_at"
+
+// apiClientDest returns the scan destinations of apiClientColumns for the given client
+func apiClientDest(client *model.APIClient) []interface{} {
+	return []interface{}{&client.ID, &client.Name, &client.Scopes, &client.KeyPrefix, &client.KeyHash, &client.CreatedAt, &client.UpdatedAt,
+		&client.LastUsedAt, &client.RevokedAt}
+}
+
+type apiClientRepository struct {
+	pgxPool *pgxpool.Pool
+}
+
+func NewAPIClientRepo(pgxPool *pgxpool.Pool) APIClientRepository {
+	return apiClientRepository{
+		pgxPool: pgxPool,
+	}
+}
+
+// GetAPIClients returns all the clients including the revoked ones ordered by id.
+func (apiClientRepo apiClientRepository) GetAPIClients(ctx context.Context) ([]model.APIClient, error) {
+	clients := []model.APIClient{}
+	rows, err := apiClientRepo.pgxPool.Query(ctx, "SELECT "+apiClientColumns+" FROM api_clients ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var client model.APIClient
+		if err = rows.Scan(apiClientDest(&client)...); err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+// GetAPIClient returns the client with the given id, pgx.ErrNoRows is returned if it doesn't exist.
+func (apiClientRepo apiClientRepository) GetAPIClient(ctx context.Context, id int64) (model.APIClient, error) {
+	var client model.APIClient
+	row := apiClientRepo.pgxPool.QueryRow(ctx, "SELECT "+apiClientColumns+" FROM api_clients WHERE id = $1", id)
+	err := row.Scan(apiClientDest(&client)...)
+	return client, err
+}
+
+// GetActiveAPIClientByKeyHash returns the client which isn't revoked with the given key hash, pgx.ErrNoRows is returned if there is none.
+func (apiClientRepo apiClientRepository) GetActiveAPIClientByKeyHash(ctx context.Context, keyHash string) (model.APIClient, error) {
+	var client model.APIClient
+	row := apiClientRepo.pgxPool.QueryRow(ctx, "SELECT "+apiClientColumns+" FROM api_clients WHERE key_hash = $1 AND revoked_at IS NULL", keyHash)
+	err := row.Scan(apiClientDest(&client)...)
+	return client, err
+}
+
+// InsertAPIClient inserts the client and returns it with its id.
+func (apiClientRepo apiClientRepository) InsertAPIClient(ctx context.Context, client model.APIClient) (model.APIClient, error) {
+	currentTime := time.Now().UTC()
+	client.CreatedAt, client.UpdatedAt = currentTime, currentTime
+	row := apiClientRepo.pgxPool.QueryRow(ctx, "INSERT INTO api_clients (name, scopes, key_prefix, key_hash, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		client.Name, client.Scopes, client.KeyPrefix, client.KeyHash, client.CreatedAt, client.UpdatedAt)
+	err := row.Scan(&client.ID)
+	return client, err
+}
+
+// RotateAPIClientKey replaces the key of the client which isn't revoked, so that its previous key can't authenticate anymore.
+// pgx.ErrNoRows is returned if the client doesn't exist or is revoked.
+func (apiClientRepo apiClientRepository) RotateAPIClientKey(ctx context.Context, id int64, keyPrefix string, keyHash string) (model.APIClient, error) {
+	var client model.APIClient
+	row := apiClientRepo.pgxPool.QueryRow(ctx, "UPDATE api_clients SET key_prefix = $1, key_hash = $2, updated_at = $3 WHERE id = $4 AND revoked_at IS NULL RETURNING "+apiClientColumns,
+		keyPrefix, keyHash, time.Now().UTC(), id)
+	err := row.Scan(apiClientDest(&client)...)
+	return client, err
+}
+
+// RevokeAPIClient revokes the client unless it is already revoked, and returns it. pgx.ErrNoRows is returned if it doesn't exist.
+func (apiClientRepo apiClientRepository) RevokeAPIClient(ctx context.Context, id int64) (model.APIClient, error) {
+	var client model.APIClient
+	currentTime := time.Now().UTC()
+	row := apiClientRepo.pgxPool.QueryRow(ctx, "UPDATE api_clients SET revoked_at = coalesce(revoked_at, $1), updated_at = CASE WHEN revoked_at IS NULL THEN $1 ELSE updated_at END WHERE id = $2 RETURNING "+apiClientColumns,
+		currentTime, id)
+	err := row.Scan(apiClientDest(&client)...)
+	return client, err
+}
+
+// TouchAPIClient records the time the client was last used.
+func (apiClientRepo apiClientRepository) TouchAPIClient(ctx context.Context, id int64, usedAt time.Time) error {
+	_, err := apiClientRepo.pgxPool.Exec(ctx, "UPDATE api_clients SET last_used_at = $1 WHERE id = $2", usedAt, id)
+	return err
+}
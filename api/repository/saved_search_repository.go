@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"strings"
+	"time"
+)
+
+type SavedSearchRepository interface {
+	GetSavedSearches(ctx context.Context, owner string) ([]model.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id int64, owner string) (model.SavedSearch, error)
+	InsertSavedSearch(ctx context.Context, savedSearch model.SavedSearch) (model.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, savedSearch model.SavedSearch, owner string) (model.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id int64, owner string) (bool, error)
+	InsertSavedSearchMatches(ctx context.Context, savedSearch model.SavedSearch, videoIDs []int64) (int64, error)
+	GetNewSavedSearchVideos(ctx context.Context, id int64, limit int) ([]model.VideoMetadata, error)
+}
+
+// savedSearchColumns are the columns of saved_searches table scanned by savedSearchDest
+const savedSearchColumns = "id, name, query, language, channel_id, keyword, owner, created_at, updated_at"
+
+// savedSearchDest returns the scan destinations of savedSearchColumns for the given saved search
+func savedSearchDest(savedSearch *model.SavedSearch) []interface{} {
+	return []interface{}{&savedSearch.ID, &savedSearch.Name, &savedSearch.Query, &savedSearch.Filters.Language, &savedSearch.Filters.ChannelID,
+		&savedSearch.Filters.Keyword, &savedSearch.Owner, &savedSearch.CreatedAt, &savedSearch.UpdatedAt}
+}
+
+type savedSearchRepository struct {
+	pgxPool *pgxpool.Pool
+}
+
+func NewSavedSearchRepo(pgxPool *pgxpool.Pool) SavedSearchRepository {
+	return savedSearchRepository{
+		pgxPool: pgxPool,
+	}
+}
+
+// GetSavedSearches returns the saved searches of the given owner, or of all the owners if it is empty, ordered by name.
+func (savedSearchRepo savedSearchRepository) GetSavedSearches(ctx context.Context, owner string) ([]model.SavedSearch, error) {
+	savedSearches := []model.SavedSearch{}
+	rows, err := savedSearchRepo.pgxPool.Query(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE $1 = '' OR owner = $1 ORDER BY name, id", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var savedSearch model.SavedSearch
+		if err = rows.Scan(savedSearchDest(&savedSearch)...); err != nil {
+			return nil, err
+		}
+		savedSearches = append(savedSearches, savedSearch)
+	}
+	return savedSearches, rows.Err()
+}
+
+// GetSavedSearch returns the saved search with the given id of the given owner, or of any owner if it is empty.
+// pgx.ErrNoRows is returned if it doesn't exist.
+func (savedSearchRepo savedSearchRepository) GetSavedSearch(ctx context.Context, id int64, owner string) (model.SavedSearch, error) {
+	var savedSearch model.SavedSearch
+	row := savedSearchRepo.pgxPool.QueryRow(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE id = $1 AND ($2 = '' OR owner = $2)", id, owner)
+	err := row.Scan(savedSearchDest(&savedSearch)...)
+	return savedSearch, err
+}
+
+// InsertSavedSearch inserts the saved search and returns it with its id.
+func (savedSearchRepo savedSearchRepository) InsertSavedSearch(ctx context.Context, savedSearch model.SavedSearch) (model.SavedSearch, error) {
+	currentTime := time.Now().UTC()
+	savedSearch.CreatedAt, savedSearch.UpdatedAt = currentTime, currentTime
+	row := savedSearchRepo.pgxPool.QueryRow(ctx, "INSERT INTO saved_searches (name, query, language, channel_id, keyword, owner, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		savedSearch.Name, savedSearch.Query, savedSearch.Filters.Language, savedSearch.Filters.ChannelID, savedSearch.Filters.Keyword, savedSearch.Owner, savedSearch.CreatedAt, savedSearch.UpdatedAt)
+	err := row.Scan(&savedSearch.ID)
+	return savedSearch, err
+}
+
+// UpdateSavedSearch updates the saved search of the given owner, or of any owner if it is empty. pgx.ErrNoRows is
+// returned if it doesn't exist.
+// The already recorded matches are kept, and only the videos inserted afterwards are matched with the updated query and filters.
+func (savedSearchRepo savedSearchRepository) UpdateSavedSearch(ctx context.Context, savedSearch model.SavedSearch, owner string) (model.SavedSearch, error) {
+	savedSearch.UpdatedAt = time.Now().UTC()
+	row := savedSearchRepo.pgxPool.QueryRow(ctx, "UPDATE saved_searches SET name = $1, query = $2, language = $3, channel_id = $4, keyword = $5, owner = $6, updated_at = $7 WHERE id = $8 AND ($9 = '' OR owner = $9) RETURNING created_at",
+		savedSearch.Name, savedSearch.Query, savedSearch.Filters.Language, savedSearch.Filters.ChannelID, savedSearch.Filters.Keyword, savedSearch.Owner, savedSearch.UpdatedAt, savedSearch.ID, owner)
+	err := row.Scan(&savedSearch.CreatedAt)
+	return savedSearch, err
+}
+
+// DeleteSavedSearch deletes the saved search of the given owner, or of any owner if it is empty, along with its
+// matches and returns whether it existed.
+func (savedSearchRepo savedSearchRepository) DeleteSavedSearch(ctx context.Context, id int64, owner string) (bool, error) {
+	tag, err := savedSearchRepo.pgxPool.Exec(ctx, "DELETE FROM saved_searches WHERE id = $1 AND ($2 = '' OR owner = $2)", id, owner)
+	return tag.RowsAffected() > 0, err
+}
+
+// InsertSavedSearchMatches records the videos of the given ids matching the query and filters of the saved search,
+// and returns the number of recorded matches.
+func (savedSearchRepo savedSearchRepository) InsertSavedSearchMatches(ctx context.Context, savedSearch model.SavedSearch, videoIDs []int64) (int64, error) {
+	var args []interface{}
+	arg := queryArgs(&args)
+	conditions, err := videoRepository{pgxPool: savedSearchRepo.pgxPool}.filterConditions(ctx, videoIDs, savedSearch.Query, savedSearch.Filters, arg)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf("INSERT INTO saved_search_matches (saved_search_id, video_id, matched_at) SELECT %s, id, %s FROM videos WHERE %s ON CONFLICT DO NOTHING",
+		arg(savedSearch.ID), arg(time.Now().UTC()), strings.Join(conditions, " AND "))
+	tag, err := savedSearchRepo.pgxPool.Exec(ctx, query, args...)
+	return tag.RowsAffected(), err
+}
+
+// GetNewSavedSearchVideos returns the latest published videos matching the saved search which are not seen yet,
+// and marks them as seen so that they are not returned again.
+func (savedSearchRepo savedSearchRepository) GetNewSavedSearchVideos(ctx context.Context, id int64, limit int) ([]model.VideoMetadata, error) {
+	videos := []model.VideoMetadata{}
+	// "SKIP LOCKED" lets concurrent requests return different new videos instead of the same ones
+	rows, err := savedSearchRepo.pgxPool.Query(ctx, `WITH seen AS (
+			UPDATE saved_search_matches SET seen_at = $3 WHERE saved_search_id = $1 AND video_id IN (
+				SELECT video_id FROM saved_search_matches JOIN videos ON videos.id = saved_search_matches.video_id
+				WHERE saved_search_id = $1 AND seen_at IS NULL ORDER BY published_at DESC, video_id DESC LIMIT $2
+				FOR UPDATE OF saved_search_matches SKIP LOCKED)
+			RETURNING video_id)
+		SELECT `+videoColumns+` FROM videos WHERE id IN (SELECT video_id FROM seen) ORDER BY published_at DESC, id DESC`, id, limit, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var video model.VideoMetadata
+		if err = rows.Scan(videoDest(&video)...); err != nil {
+			return nil, err
+		}
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
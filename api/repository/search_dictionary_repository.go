@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type SearchDictionaryRepository interface {
+	GetSynonyms(ctx context.Context) ([]model.SearchSynonym, error)
+	GetSynonym(ctx context.Context, id int64) (model.SearchSynonym, error)
+	InsertSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error)
+	UpdateSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error)
+	DeleteSynonym(ctx context.Context, id int64) (bool, error)
+	GetStopwords(ctx context.Context) ([]model.SearchStopword, error)
+	InsertStopword(ctx context.Context, word string) (model.SearchStopword, error)
+	DeleteStopword(ctx context.Context, word string) (bool, error)
+}
+
+type searchDictionaryRepository struct {
+	pgxPool *pgxpool.Pool
+}
+
+func NewSearchDictionaryRepo(pgxPool *pgxpool.Pool) SearchDictionaryRepository {
+	return searchDictionaryRepository{
+		pgxPool: pgxPool,
+	}
+}
+
+// GetSynonyms returns all the search synonyms ordered by term.
+func (dictionaryRepo searchDictionaryRepository) GetSynonyms(ctx context.Context) ([]model.SearchSynonym, error) {
+	synonyms := []model.SearchSynonym{}
+	rows, err := dictionaryRepo.pgxPool.Query(ctx, "SELECT id, term, synonyms, created_at, updated_at FROM search_synonyms ORDER BY term")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var synonym model.SearchSynonym
+		err = rows.Scan(&synonym.ID, &synonym.Term, &synonym.Synonyms, &synonym.CreatedAt, &synonym.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		synonyms = append(synonyms, synonym)
+	}
+	return synonyms, nil
+}
+
+// GetSynonym returns the search synonym with the given id, pgx.ErrNoRows is returned if it doesn't exist.
+func (dictionaryRepo searchDictionaryRepository) GetSynonym(ctx context.Context, id int64) (model.SearchSynonym, error) {
+	var synonym model.SearchSynonym
+	row := dictionaryRepo.pgxPool.QueryRow(ctx, "SELECT id, term, synonyms, created_at, updated_at FROM search_synonyms WHERE id = $1", id)
+	err := row.Scan(&synonym.ID, &synonym.Term, &synonym.Synonyms, &synonym.CreatedAt, &synonym.UpdatedAt)
+	return synonym, err
+}
+
+// InsertSynonym inserts the search synonym and returns it with its id.
+func (dictionaryRepo searchDictionaryRepository) InsertSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error) {
+	currentTime := time.Now().UTC()
+	synonym.CreatedAt, synonym.UpdatedAt = currentTime, currentTime
+	row := dictionaryRepo.pgxPool.QueryRow(ctx, "INSERT INTO search_synonyms (term, synonyms, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id", synonym.Term, synonym.Synonyms, synonym.CreatedAt, synonym.UpdatedAt)
+	err := row.Scan(&synonym.ID)
+	return synonym, err
+}
+
+// UpdateSynonym updates the term and synonyms of the search synonym, pgx.ErrNoRows is returned if it doesn't exist.
+func (dictionaryRepo searchDictionaryRepository) UpdateSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error) {
+	synonym.UpdatedAt = time.Now().UTC()
+	row := dictionaryRepo.pgxPool.QueryRow(ctx, "UPDATE search_synonyms SET term = $1, synonyms = $2, updated_at = $3 WHERE id = $4 RETURNING created_at", synonym.Term, synonym.Synonyms, synonym.UpdatedAt, synonym.ID)
+	err := row.Scan(&synonym.CreatedAt)
+	return synonym, err
+}
+
+// DeleteSynonym deletes the search synonym and returns whether it existed.
+func (dictionaryRepo searchDictionaryRepository) DeleteSynonym(ctx context.Context, id int64) (bool, error) {
+	tag, err := dictionaryRepo.pgxPool.Exec(ctx, "DELETE FROM search_synonyms WHERE id = $1", id)
+	return tag.RowsAffected() > 0, err
+}
+
+// GetStopwords returns all the search stopwords ordered alphabetically.
+func (dictionaryRepo searchDictionaryRepository) GetStopwords(ctx context.Context) ([]model.SearchStopword, error) {
+	stopwords := []model.SearchStopword{}
+	rows, err := dictionaryRepo.pgxPool.Query(ctx, "SELECT word, created_at FROM search_stopwords ORDER BY word")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var stopword model.SearchStopword
+		err = rows.Scan(&stopword.Word, &stopword.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		stopwords = append(stopwords, stopword)
+	}
+	return stopwords, nil
+}
+
+// InsertStopword inserts the search stopword, adding an existing stopword returns the existing one.
+func (dictionaryRepo searchDictionaryRepository) InsertStopword(ctx context.Context, word string) (model.SearchStopword, error) {
+	stopword := model.SearchStopword{Word: word}
+	// "DO UPDATE" is a no-op which lets the existing stopword be returned
+	row := dictionaryRepo.pgxPool.QueryRow(ctx, "INSERT INTO search_stopwords (word, created_at) VALUES ($1, $2) ON CONFLICT (word) DO UPDATE SET word = EXCLUDED.word RETURNING created_at", word, time.Now().UTC())
+	err := row.Scan(&stopword.CreatedAt)
+	return stopword, err
+}
+
+// DeleteStopword deletes the search stopword and returns whether it existed.
+func (dictionaryRepo searchDictionaryRepository) DeleteStopword(ctx context.Context, word string) (bool, error) {
+	tag, err := dictionaryRepo.pgxPool.Exec(ctx, "DELETE FROM search_stopwords WHERE word = $1", word)
+	return tag.RowsAffected() > 0, err
+}
@@ -2,22 +2,214 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/utils"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"strings"
 	"time"
 )
 
 type VideoRepository interface {
-	InsertVideos(videos []model.VideoMetadata) error
-	InsertNextPageToken(pageToken string, publishedAfterDateTime time.Time) error
-	GetAvailableLastPageToken() (pageToken string, publishedAfterDateTime time.Time, err error)
-	MarkPageTokenAsUsed(pageToken string) error
-	GetVideos(limit int, offset int) ([]model.VideoMetadata, error)
-	SearchVideos(searchString string) ([]model.VideoMetadata, error)
-	GetLastPublishedAtDateTime() (time.Time, error)
+	InsertVideos(ctx context.Context, videos []model.VideoMetadata) ([]model.VideoMetadata, error)
+	InsertNextPageToken(ctx context.Context, pageToken string, publishedAfterDateTime time.Time) error
+	GetAvailableLastPageToken(ctx context.Context) (pageToken string, publishedAfterDateTime time.Time, err error)
+	MarkPageTokenAsUsed(ctx context.Context, pageToken string) error
+	GetVideos(ctx context.Context, videosRequest model.GetVideosRequest) ([]model.VideoMetadata, error)
+	ExportVideos(ctx context.Context, videosRequest model.GetVideosRequest, each func(video model.VideoMetadata) error) error
+	GetVideoFingerprints(ctx context.Context, bands [4][]int64) ([]model.VideoFingerprint, error)
+	FilterVideos(ctx context.Context, videoIDs []int64, query string, filters model.VideoFilters) ([]int64, error)
+	GetLastVideoID(ctx context.Context) (int64, error)
+	GetVideosAfter(ctx context.Context, afterID int64, query string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error)
+	GetLatestVideos(ctx context.Context, query string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error)
+	GetVideo(ctx context.Context, youtubeID string) (model.VideoMetadata, error)
+	GetSimilarVideos(ctx context.Context, youtubeID string, limit int, publishedAfter time.Time, publishedBefore time.Time) ([]model.VideoMetadata, error)
+	SearchVideos(ctx context.Context, searchRequest model.SearchVideosRequest, fuzzy bool) ([]model.VideoMetadata, error)
+	CountSearchVideos(ctx context.Context, searchRequest model.SearchVideosRequest) (int, error)
+	GetSearchFacets(ctx context.Context, searchRequest model.SearchVideosRequest, fuzzy bool) (map[string][]model.FacetBucket, error)
+	GetSpellingSuggestion(ctx context.Context, words []string) (string, error)
+	InsertSearchQuery(ctx context.Context, query string) error
+	SuggestTitles(ctx context.Context, words string, prefix string, limit int) ([]string, error)
+	SuggestQueries(ctx context.Context, prefix string, limit int) ([]string, error)
+	GetLastPublishedAtDateTime(ctx context.Context) (time.Time, error)
+	ReindexVideos(ctx context.Context, batchSize int) (int64, error)
 }
 
+// videoColumns are the columns of videos table scanned by videoDest
+const videoColumns = "id, youtube_id, title, description, published_at, thumbnail_url, language, channel_id, channel_title, duration_seconds, keyword, view_count, cluster_id"
+
+// videoDest returns the scan destinations of videoColumns for the given video
+func videoDest(video *model.VideoMetadata) []interface{} {
+	return []interface{}{&video.ID, &video.YoutubeID, &video.Title, &video.Description, &video.PublishedAt, &video.ThumbnailURL, &video.Language,
+		&video.ChannelID, &video.ChannelTitle, &video.DurationSeconds, &video.Keyword, &video.ViewCount, &video.ClusterID}
+}
+
+// queryArgs returns a function which appends the given value to args and returns its query placeholder
+func queryArgs(args *[]interface{}) func(value interface{}) string {
+	return func(value interface{}) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+}
+
+// match of the videos for a search request
+type match struct {
+	// tsQuery is the tsquery expression of the search string
+	tsQuery string
+	// condition matches the videos, and rank is their relevance score computed from the text rank,
+	// recency factor and popularity factor expressions
+	condition        string
+	rank             string
+	textRank         string
+	recencyFactor    string
+	popularityFactor string
+}
+
+// searchMatch returns the match of the videos for the given search request, whose search string is bound to a query
+// argument along with the other values of the match. The synonyms and stopwords of the search string are looked up
+// in the search dictionary.
+func (videoRepo videoRepository) searchMatch(ctx context.Context, searchRequest model.SearchVideosRequest, fuzzy bool, arg func(value interface{}) string) (match, error) {
+	synonyms, stopwords, err := videoRepo.getSynonymsAndStopwords(ctx, utils.SplitWords(searchRequest.SearchString))
+	if err != nil {
+		return match{}, err
+	}
+	return newSearchMatch(searchRequest, synonyms, stopwords, fuzzy, arg), nil
+}
+
+// newSearchMatch returns the match of the videos for the given search request, expanding the search terms with their
+// synonyms and removing the stopwords. When fuzzy is true, videos with title similar to the search string are matched
+// along with the full text search hits. Videos are ranked with the ranking options of the search request, or by
+// ts_rank only if they are not set.
+func newSearchMatch(searchRequest model.SearchVideosRequest, synonyms map[string][]string, stopwords map[string]bool, fuzzy bool, arg func(value interface{}) string) match {
+	searchString := arg(searchRequest.SearchString)
+	tsQuery := searchTsQuery(searchRequest, searchString, synonyms, stopwords, arg)
+	m := match{
+		tsQuery:          tsQuery,
+		condition:        "document_with_weights @@ " + tsQuery,
+		textRank:         fmt.Sprintf("ts_rank(document_with_weights, %s)", tsQuery),
+		recencyFactor:    "1",
+		popularityFactor: "1",
+	}
+	ranking := searchRequest.Ranking
+	if ranking != nil {
+		// weights are in the order of D, C, B and A labels, title is labeled A and description B
+		weights := []float32{0.1, 0.2, float32(*ranking.DescriptionWeight), float32(*ranking.TitleWeight)}
+		// the function is validated to be either ts_rank or ts_rank_cd
+		m.textRank = fmt.Sprintf("%s(%s::float4[], document_with_weights, %s, %s)", ranking.Function, arg(weights), tsQuery, arg(*ranking.Normalization))
+	}
+	if fuzzy {
+		// "<%" uses the trigram index on title to match the misspelled words of the search string
+		m.condition = fmt.Sprintf("(%s OR %s <%% title)", m.condition, searchString)
+		m.textRank = fmt.Sprintf("(%s + word_similarity(%s, title))", m.textRank, searchString)
+	}
+	m.rank = m.textRank
+	if ranking != nil && ranking.RecencyHalfLifeHours != nil && *ranking.RecencyBoost > 0 {
+		// the recency decays by half every half-life since the video is published
+		m.recencyFactor = fmt.Sprintf("(1 + %s * power(0.5, greatest(extract(epoch FROM (now() AT TIME ZONE 'UTC') - published_at), 0) / 3600 / %s))",
+			arg(*ranking.RecencyBoost), arg(*ranking.RecencyHalfLifeHours))
+		m.rank += " * " + m.recencyFactor
+	}
+	if ranking != nil && *ranking.PopularityBoost > 0 {
+		m.popularityFactor = fmt.Sprintf("(1 + %s * ln(1 + coalesce(view_count, 0)))", arg(*ranking.PopularityBoost))
+		m.rank += " * " + m.popularityFactor
+	}
+	return m
+}
+
+// searchTsQuery returns the tsquery expression of the search string of the given search request, which is bound to
+// the searchString query placeholder. Search terms are expanded with their synonyms and the stopwords are removed.
+func searchTsQuery(searchRequest model.SearchVideosRequest, searchString string, synonyms map[string][]string, stopwords map[string]bool, arg func(value interface{}) string) string {
+	config := ""
+	if searchRequest.Language != "" {
+		textSearchConfig, _ := utils.TextSearchConfig(searchRequest.Language)
+		config = arg(textSearchConfig) + "::regconfig, "
+	}
+	if len(synonyms) == 0 && len(stopwords) == 0 {
+		return fmt.Sprintf("plainto_tsquery(%s%s)", config, searchString)
+	}
+	terms := utils.ExpandSynonyms(utils.SplitWords(searchRequest.SearchString), synonyms, stopwords)
+	if len(terms) == 0 {
+		// the search string has only stopwords
+		return "''::tsquery"
+	}
+	termQueries := make([]string, 0, len(terms))
+	for _, phrases := range terms {
+		phraseQueries := make([]string, 0, len(phrases))
+		for _, phrase := range phrases {
+			phraseQueries = append(phraseQueries, fmt.Sprintf("phraseto_tsquery(%s%s)", config, arg(phrase)))
+		}
+		termQueries = append(termQueries, "("+strings.Join(phraseQueries, " || ")+")")
+	}
+	return "(" + strings.Join(termQueries, " && ") + ")"
+}
+
+// filterConditions returns the conditions of the videos matching the query and filters the same way as the search API
+// does, an empty query matches all the videos. When video ids are given, only the videos of the ids are matched.
+func (videoRepo videoRepository) filterConditions(ctx context.Context, videoIDs []int64, query string, filters model.VideoFilters, arg func(value interface{}) string) ([]string, error) {
+	var conditions []string
+	if query != "" {
+		searchRequest := model.SearchVideosRequest{SearchString: query}
+		if filters.Language != nil {
+			searchRequest.Language = *filters.Language
+		}
+		match, err := videoRepo.searchMatch(ctx, searchRequest, false, arg)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, match.condition)
+	}
+	if videoIDs != nil {
+		conditions = append(conditions, "id = ANY("+arg(videoIDs)+")")
+	}
+	if filters.ChannelID != nil {
+		conditions = append(conditions, "channel_id = "+arg(*filters.ChannelID))
+	}
+	if filters.Keyword != nil {
+		conditions = append(conditions, "keyword = "+arg(*filters.Keyword))
+	}
+	return conditions, nil
+}
+
+// getSynonymsAndStopwords returns the synonyms of the search terms and the stopwords among the given words of a search string.
+func (videoRepo videoRepository) getSynonymsAndStopwords(ctx context.Context, words []string) (map[string][]string, map[string]bool, error) {
+	synonyms := make(map[string][]string)
+	stopwords := make(map[string]bool)
+	if len(words) == 0 {
+		return synonyms, stopwords, nil
+	}
+	rows, err := videoRepo.pgxPool.Query(ctx, "SELECT term, synonyms FROM search_synonyms WHERE term = ANY($1)", utils.SearchTerms(words))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var term string
+		var termSynonyms []string
+		if err = rows.Scan(&term, &termSynonyms); err != nil {
+			return nil, nil, err
+		}
+		synonyms[term] = termSynonyms
+	}
+	rows.Close()
+	rows, err = videoRepo.pgxPool.Query(ctx, "SELECT word FROM search_stopwords WHERE word = ANY($1)", words)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var word string
+		if err = rows.Scan(&word); err != nil {
+			return nil, nil, err
+		}
+		stopwords[word] = true
+	}
+	return synonyms, stopwords, nil
+}
+
+// VideosInsertedChannel is the notification channel of the ids of the inserted videos
+const VideosInsertedChannel = "videos_inserted"
+
 type videoRepository struct {
 	pgxPool *pgxpool.Pool
 }
@@ -28,28 +220,38 @@ func NewVideoRepo(pgxPool *pgxpool.Pool) VideoRepository {
 	}
 }
 
-// InsertVideos batch inserts videos into the database.
-func (videoRepo videoRepository) InsertVideos(videos []model.VideoMetadata) error {
+// InsertVideos batch inserts videos into the database, and returns the inserted videos with their ids.
+// Videos which are already stored are skipped.
+func (videoRepo videoRepository) InsertVideos(ctx context.Context, videos []model.VideoMetadata) ([]model.VideoMetadata, error) {
 	batch := &pgx.Batch{}
 	for _, video := range videos {
 		currentTime := time.Now().UTC()
 		// here we are using "ON CONFLICT DO NOTHING" to avoid duplicate entries/duplicate primary key violation errors
-		batch.Queue("INSERT INTO videos (youtube_id, title, description, published_at, created_at, updated_at, thumbnail_url) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING", video.YoutubeID, video.Title, video.Description, video.PublishedAt, currentTime, currentTime, video.ThumbnailURL)
+		// a near-duplicate joins the cluster of the video it duplicates, which is either already stored or queued earlier in
+		// this batch. Otherwise the cluster_id is NULL, and the cluster_update trigger starts a new cluster of the video.
+		batch.Queue("INSERT INTO videos (youtube_id, title, description, published_at, created_at, updated_at, thumbnail_url, language, text_search_config, channel_id, channel_title, duration_seconds, keyword, view_count, simhash, cluster_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::regconfig, $10, $11, $12, $13, $14, $15, (SELECT cluster_id FROM videos WHERE youtube_id = $16)) ON CONFLICT DO NOTHING RETURNING id, cluster_id",
+			video.YoutubeID, video.Title, video.Description, video.PublishedAt, currentTime, currentTime, video.ThumbnailURL, video.Language, utils.VideoTextSearchConfig(video.Language), video.ChannelID, video.ChannelTitle, video.DurationSeconds, video.Keyword, video.ViewCount, video.Fingerprint, video.DuplicateOf)
 	}
-	result := videoRepo.pgxPool.SendBatch(context.Background(), batch)
-	for i := 0; i < batch.Len(); i++ {
-		_, err := result.Exec()
+	result := videoRepo.pgxPool.SendBatch(ctx, batch)
+	defer result.Close()
+	insertedVideos := []model.VideoMetadata{}
+	for _, video := range videos {
+		// no row is returned when the video is skipped on conflict
+		err := result.QueryRow().Scan(&video.ID, &video.ClusterID)
+		if err == pgx.ErrNoRows {
+			continue
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
+		insertedVideos = append(insertedVideos, video)
 	}
-	defer result.Close()
-	return nil
+	return insertedVideos, nil
 }
 
 // GetAvailableLastPageToken returns the next page token that is not used.
-func (videoRepo videoRepository) GetAvailableLastPageToken() (pageToken string, publishedAfterDateTime time.Time, err error) {
-	row := videoRepo.pgxPool.QueryRow(context.Background(), "SELECT next_page_token, published_after_time FROM page_tokens WHERE is_used = false ORDER BY created_at DESC LIMIT 1")
+func (videoRepo videoRepository) GetAvailableLastPageToken(ctx context.Context) (pageToken string, publishedAfterDateTime time.Time, err error) {
+	row := videoRepo.pgxPool.QueryRow(ctx, "SELECT next_page_token, published_after_time FROM page_tokens WHERE is_used = false ORDER BY created_at DESC LIMIT 1")
 	err = row.Scan(&pageToken, &publishedAfterDateTime)
 	if err != nil && err != pgx.ErrNoRows {
 		return "", time.Time{}, err
@@ -58,28 +260,50 @@ func (videoRepo videoRepository) GetAvailableLastPageToken() (pageToken string,
 }
 
 // InsertNextPageToken inserts the next page token into the database.
-func (videoRepo videoRepository) InsertNextPageToken(pageToken string, publishedAfterDateTime time.Time) error {
-	_, err := videoRepo.pgxPool.Exec(context.Background(), "INSERT INTO page_tokens (next_page_token, published_after_time, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", pageToken, publishedAfterDateTime.Format(time.RFC3339), time.Now().UTC())
+func (videoRepo videoRepository) InsertNextPageToken(ctx context.Context, pageToken string, publishedAfterDateTime time.Time) error {
+	_, err := videoRepo.pgxPool.Exec(ctx, "INSERT INTO page_tokens (next_page_token, published_after_time, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", pageToken, publishedAfterDateTime.Format(time.RFC3339), time.Now().UTC())
 	return err
 }
 
 // MarkPageTokenAsUsed marks the page token as used.
-func (videoRepo videoRepository) MarkPageTokenAsUsed(pageToken string) error {
-	_, err := videoRepo.pgxPool.Exec(context.Background(), "UPDATE page_tokens SET is_used = true WHERE next_page_token = $1", pageToken)
+func (videoRepo videoRepository) MarkPageTokenAsUsed(ctx context.Context, pageToken string) error {
+	_, err := videoRepo.pgxPool.Exec(ctx, "UPDATE page_tokens SET is_used = true WHERE next_page_token = $1", pageToken)
 	return err
 }
 
+// videosQuery returns the query of the videos ordered by publish time, whose limit and offset are the first and second
+// query arguments. When collapse is true, only the first published video of each cluster is selected with its number of duplicates.
+func videosQuery(collapse bool) string {
+	if collapse {
+		return `SELECT ` + videoColumns + `, (SELECT count(*) - 1 FROM videos AS duplicate WHERE duplicate.cluster_id = videos.cluster_id) FROM videos
+			WHERE NOT EXISTS (SELECT 1 FROM videos AS earlier WHERE earlier.cluster_id = videos.cluster_id AND (earlier.published_at, earlier.id) < (videos.published_at, videos.id))
+			ORDER BY published_at DESC LIMIT $1 OFFSET $2`
+	}
+	return "SELECT " + videoColumns + " FROM videos ORDER BY published_at DESC LIMIT $1 OFFSET $2"
+}
+
+// videosDest returns the scan destinations of videosQuery for the given video
+func videosDest(video *model.VideoMetadata, collapse bool) []interface{} {
+	dest := videoDest(video)
+	if collapse {
+		video.Duplicates = new(int)
+		dest = append(dest, video.Duplicates)
+	}
+	return dest
+}
+
 // GetVideos returns the videos from the database.
-func (videoRepo videoRepository) GetVideos(limit int, offset int) ([]model.VideoMetadata, error) {
+// When the near-duplicates are collapsed, only the first published video of each cluster is returned with its number of duplicates.
+func (videoRepo videoRepository) GetVideos(ctx context.Context, videosRequest model.GetVideosRequest) ([]model.VideoMetadata, error) {
 	videos := []model.VideoMetadata{}
-	rows, err := videoRepo.pgxPool.Query(context.Background(), "SELECT id, youtube_id, title, description, published_at, thumbnail_url FROM videos ORDER BY published_at DESC LIMIT $1 OFFSET $2", limit, offset)
+	rows, err := videoRepo.pgxPool.Query(ctx, videosQuery(videosRequest.Collapse), videosRequest.Limit, videosRequest.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var video model.VideoMetadata
-		err = rows.Scan(&video.ID, &video.YoutubeID, &video.Title, &video.Description, &video.PublishedAt, &video.ThumbnailURL)
+		err = rows.Scan(videosDest(&video, videosRequest.Collapse)...)
 		if err != nil {
 			return nil, err
 		}
@@ -88,17 +312,279 @@ func (videoRepo videoRepository) GetVideos(limit int, offset int) ([]model.Video
 	return videos, nil
 }
 
+// exportFetchSize is the number of videos fetched from the export cursor at once
+const exportFetchSize = 1000
+
+// ExportVideos calls each for the videos of GetVideos one by one, reading them from a cursor so that all the videos
+// can be exported without loading them in memory. A zero limit exports all the videos after the offset.
+// Exporting stops at the first error returned by each, or when the context is done.
+func (videoRepo videoRepository) ExportVideos(ctx context.Context, videosRequest model.GetVideosRequest, each func(video model.VideoMetadata) error) error {
+	tx, err := videoRepo.pgxPool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	// the transaction only reads, so it is always rolled back, which closes the cursor too
+	defer tx.Rollback(context.Background())
+	var limit interface{}
+	if videosRequest.Limit > 0 {
+		limit = videosRequest.Limit
+	}
+	// LIMIT NULL is the same as no limit
+	_, err = tx.Exec(ctx, "DECLARE export_videos NO SCROLL CURSOR FOR "+videosQuery(videosRequest.Collapse), limit, videosRequest.Offset)
+	if err != nil {
+		return err
+	}
+	fetch := fmt.Sprintf("FETCH %d FROM export_videos", exportFetchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		fetched := 0
+		for rows.Next() {
+			var video model.VideoMetadata
+			if err = rows.Scan(videosDest(&video, videosRequest.Collapse)...); err != nil {
+				rows.Close()
+				return err
+			}
+			fetched++
+			if err = each(video); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
+
+// GetVideoFingerprints returns the fingerprints of the stored videos sharing any of the given bands, where bands[i]
+// are the i-th 16-bit bands of the fingerprints whose near-duplicates are looked up.
+func (videoRepo videoRepository) GetVideoFingerprints(ctx context.Context, bands [4][]int64) ([]model.VideoFingerprint, error) {
+	fingerprints := []model.VideoFingerprint{}
+	rows, err := videoRepo.pgxPool.Query(ctx, `SELECT youtube_id, simhash FROM videos
+		WHERE (simhash & 65535) = ANY($1) OR ((simhash >> 16) & 65535) = ANY($2) OR ((simhash >> 32) & 65535) = ANY($3) OR ((simhash >> 48) & 65535) = ANY($4)`,
+		bands[0], bands[1], bands[2], bands[3])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fingerprint model.VideoFingerprint
+		if err = rows.Scan(&fingerprint.YoutubeID, &fingerprint.Fingerprint); err != nil {
+			return nil, err
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	return fingerprints, rows.Err()
+}
+
+// FilterVideos returns the ids of the videos of the given ids matching the query and filters, an empty query matches all the videos.
+func (videoRepo videoRepository) FilterVideos(ctx context.Context, videoIDs []int64, query string, filters model.VideoFilters) ([]int64, error) {
+	if videoIDs == nil {
+		videoIDs = []int64{}
+	}
+	var args []interface{}
+	arg := queryArgs(&args)
+	conditions, err := videoRepo.filterConditions(ctx, videoIDs, query, filters, arg)
+	if err != nil {
+		return nil, err
+	}
+	matchingIDs := []int64{}
+	rows, err := videoRepo.pgxPool.Query(ctx, "SELECT id FROM videos WHERE "+strings.Join(conditions, " AND ")+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		matchingIDs = append(matchingIDs, id)
+	}
+	return matchingIDs, rows.Err()
+}
+
+// GetLastVideoID returns the id of the last inserted video, or 0 if there are no videos.
+func (videoRepo videoRepository) GetLastVideoID(ctx context.Context) (int64, error) {
+	row := videoRepo.pgxPool.QueryRow(ctx, "SELECT coalesce(max(id), 0) FROM videos")
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+// GetVideosAfter returns the videos inserted after the video of the given id in the order they were inserted, which
+// match the query and filters. An empty query matches all the videos.
+func (videoRepo videoRepository) GetVideosAfter(ctx context.Context, afterID int64, query string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error) {
+	videos := []model.VideoMetadata{}
+	var args []interface{}
+	arg := queryArgs(&args)
+	conditions, err := videoRepo.filterConditions(ctx, nil, query, filters, arg)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, "id > "+arg(afterID))
+	rows, err := videoRepo.pgxPool.Query(ctx, fmt.Sprintf("SELECT %s FROM videos WHERE %s ORDER BY id LIMIT %s",
+		videoColumns, strings.Join(conditions, " AND "), arg(limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var video model.VideoMetadata
+		if err = rows.Scan(videoDest(&video)...); err != nil {
+			return nil, err
+		}
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
+
+// GetLatestVideos returns the latest published videos matching the query and filters with their insertion time, an empty
+// query matches all the videos.
+func (videoRepo videoRepository) GetLatestVideos(ctx context.Context, query string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error) {
+	videos := []model.VideoMetadata{}
+	var args []interface{}
+	arg := queryArgs(&args)
+	conditions, err := videoRepo.filterConditions(ctx, nil, query, filters, arg)
+	if err != nil {
+		return nil, err
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := videoRepo.pgxPool.Query(ctx, fmt.Sprintf("SELECT %s, created_at FROM videos %s ORDER BY published_at DESC, id DESC LIMIT %s",
+		videoColumns, where, arg(limit)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var video model.VideoMetadata
+		if err = rows.Scan(append(videoDest(&video), &video.CreatedAt)...); err != nil {
+			return nil, err
+		}
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
+
+// GetVideo returns the video of the given YouTube id, pgx.ErrNoRows is returned if it doesn't exist.
+func (videoRepo videoRepository) GetVideo(ctx context.Context, youtubeID string) (model.VideoMetadata, error) {
+	var video model.VideoMetadata
+	row := videoRepo.pgxPool.QueryRow(ctx, "SELECT "+videoColumns+" FROM videos WHERE youtube_id = $1", youtubeID)
+	err := row.Scan(videoDest(&video)...)
+	return video, err
+}
+
+// GetSimilarVideos returns the videos related to the video of the given YouTube id, matching any of the most
+// frequent lexemes of its title and description, or having a similar title. The video itself and other uploads
+// of it having the same title are excluded. Zero published after and before times are ignored.
+func (videoRepo videoRepository) GetSimilarVideos(ctx context.Context, youtubeID string, limit int, publishedAfter time.Time, publishedBefore time.Time) ([]model.VideoMetadata, error) {
+	videos := []model.VideoMetadata{}
+	var args []interface{}
+	arg := queryArgs(&args)
+	conditions := []string{"id <> (SELECT id FROM source)", "lower(title) <> (SELECT lower(title) FROM source)",
+		"((SELECT query FROM terms) IS NOT NULL AND document_with_weights @@ (SELECT query FROM terms) OR title % (SELECT title FROM source))"}
+	query := fmt.Sprintf(`WITH source AS (SELECT id, title, document_with_weights FROM videos WHERE youtube_id = %s),
+		terms AS (SELECT string_agg(quote_literal(lexeme), ' | ')::tsquery AS query FROM (
+			SELECT lexeme FROM source, unnest(document_with_weights)
+			WHERE length(lexeme) >= 3 ORDER BY cardinality(array_positions(weights, 'A')) DESC, cardinality(positions) DESC, lexeme LIMIT 12) AS top_lexemes)`,
+		arg(youtubeID))
+	if !publishedAfter.IsZero() {
+		conditions = append(conditions, "published_at >= "+arg(publishedAfter))
+	}
+	if !publishedBefore.IsZero() {
+		conditions = append(conditions, "published_at < "+arg(publishedBefore))
+	}
+	// re-uploads of a video share its title, so only the latest video of each title is returned
+	query += fmt.Sprintf(` SELECT %[1]s FROM (
+			SELECT DISTINCT ON (lower(title)) %[1]s, ts_rank(document_with_weights, coalesce((SELECT query FROM terms), ''::tsquery)) + similarity(title, (SELECT title FROM source)) AS rank
+			FROM videos WHERE %[2]s ORDER BY lower(title), published_at DESC) AS similar
+		ORDER BY rank DESC, published_at DESC LIMIT %[3]s`, videoColumns, strings.Join(conditions, " AND "), arg(limit))
+	rows, err := videoRepo.pgxPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var video model.VideoMetadata
+		if err = rows.Scan(videoDest(&video)...); err != nil {
+			return nil, err
+		}
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
+
 // SearchVideos search videos from the database using full text search based on given search string.
-func (videoRepo videoRepository) SearchVideos(searchString string) ([]model.VideoMetadata, error) {
+// When fuzzy is true, videos with title similar to the search string are blended in with the full text search hits.
+// When highlight options are given, ts_headline fragments are generated only for the returned page of videos.
+func (videoRepo videoRepository) SearchVideos(ctx context.Context, searchRequest model.SearchVideosRequest, fuzzy bool) ([]model.VideoMetadata, error) {
 	videos := []model.VideoMetadata{}
-	rows, err := videoRepo.pgxPool.Query(context.Background(), "SELECT id, youtube_id, title, description, published_at, thumbnail_url FROM videos WHERE document_with_weights @@ plainto_tsquery($1) ORDER BY ts_rank(document_with_weights, plainto_tsquery($1)) DESC, published_at DESC", searchString)
+	var args []interface{}
+	arg := queryArgs(&args)
+	match, err := videoRepo.searchMatch(ctx, searchRequest, fuzzy, arg)
+	if err != nil {
+		return nil, err
+	}
+	scoreColumns := ""
+	if searchRequest.Explain {
+		scoreColumns = fmt.Sprintf(", %s::float8 AS text_rank, %s::float8 AS recency_factor, %s::float8 AS popularity_factor", match.textRank, match.recencyFactor, match.popularityFactor)
+	}
+	page := fmt.Sprintf("SELECT %s, text_search_config, %s AS rank%s FROM videos WHERE %s ORDER BY rank DESC, published_at DESC LIMIT %s OFFSET %s",
+		videoColumns, match.rank, scoreColumns, match.condition, arg(searchRequest.Limit), arg(searchRequest.Offset))
+	if searchRequest.Collapse {
+		// the best ranked match of each cluster represents it, along with the number of other matches in the cluster
+		page = fmt.Sprintf(`SELECT * FROM (
+				SELECT %s, text_search_config, %s AS rank%s, count(*) OVER (PARTITION BY cluster_id) - 1 AS duplicates,
+					row_number() OVER (PARTITION BY cluster_id ORDER BY %s DESC, published_at DESC) AS cluster_rank
+				FROM videos WHERE %s) AS matches
+			WHERE cluster_rank = 1 ORDER BY rank DESC, published_at DESC LIMIT %s OFFSET %s`,
+			videoColumns, match.rank, scoreColumns, match.rank, match.condition, arg(searchRequest.Limit), arg(searchRequest.Offset))
+	}
+	columns := videoColumns
+	if searchRequest.Collapse {
+		columns += ", duplicates"
+	}
+	if searchRequest.Explain {
+		columns += ", rank::float8, text_rank, recency_factor, popularity_factor"
+	}
+	if searchRequest.Highlight != nil {
+		// ts_headline is expensive, so it is computed in the outer query over the already paginated rows only.
+		// titles are short, so they are always highlighted as a whole
+		columns += fmt.Sprintf(", ts_headline(text_search_config, title, %[1]s, %[2]s), ts_headline(text_search_config, description, %[1]s, %[3]s)",
+			match.tsQuery, arg(headlineOptions(*searchRequest.Highlight, 0)), arg(headlineOptions(*searchRequest.Highlight, searchRequest.Highlight.MaxFragments)))
+	}
+	query := fmt.Sprintf("SELECT %s FROM (%s) AS page ORDER BY rank DESC, published_at DESC", columns, page)
+	rows, err := videoRepo.pgxPool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var video model.VideoMetadata
-		err = rows.Scan(&video.ID, &video.YoutubeID, &video.Title, &video.Description, &video.PublishedAt, &video.ThumbnailURL)
+		dest := videoDest(&video)
+		if searchRequest.Collapse {
+			video.Duplicates = new(int)
+			dest = append(dest, video.Duplicates)
+		}
+		if searchRequest.Explain {
+			video.Score = &model.SearchScore{}
+			dest = append(dest, &video.Score.Score, &video.Score.TextRank, &video.Score.RecencyFactor, &video.Score.PopularityFactor)
+		}
+		if searchRequest.Highlight != nil {
+			video.Highlight = &model.VideoHighlight{}
+			dest = append(dest, &video.Highlight.Title, &video.Highlight.Description)
+		}
+		err = rows.Scan(dest...)
 		if err != nil {
 			return nil, err
 		}
@@ -107,9 +593,144 @@ func (videoRepo videoRepository) SearchVideos(searchString string) ([]model.Vide
 	return videos, nil
 }
 
+// CountSearchVideos returns the number of videos matching the search string of the given search request using full text search.
+func (videoRepo videoRepository) CountSearchVideos(ctx context.Context, searchRequest model.SearchVideosRequest) (int, error) {
+	var args []interface{}
+	arg := queryArgs(&args)
+	match, err := videoRepo.searchMatch(ctx, searchRequest, false, arg)
+	if err != nil {
+		return 0, err
+	}
+	row := videoRepo.pgxPool.QueryRow(ctx, "SELECT count(*) FROM videos WHERE "+match.condition, args...)
+	var count int
+	err = row.Scan(&count)
+	return count, err
+}
+
+// facetQueries are the queries of the buckets of each search facet over the "matches" of a search
+var facetQueries = map[string]string{
+	model.FacetChannel: `SELECT 'channel', channel_id, max(channel_title), count(*) FROM matches
+		WHERE channel_id IS NOT NULL GROUP BY channel_id ORDER BY count(*) DESC, channel_id LIMIT 10`,
+	model.FacetPublishMonth: `SELECT 'publishMonth', to_char(published_at, 'YYYY-MM'), NULL::varchar, count(*) FROM matches
+		GROUP BY 2 ORDER BY 2 DESC LIMIT 24`,
+	// buckets are the same as the video duration filter of YouTube
+	model.FacetDuration: `SELECT 'duration', CASE WHEN duration_seconds IS NULL THEN 'unknown' WHEN duration_seconds < 240 THEN 'short'
+			WHEN duration_seconds <= 1200 THEN 'medium' ELSE 'long' END, NULL::varchar, count(*) FROM matches
+		GROUP BY 2 ORDER BY count(*) DESC`,
+	model.FacetKeyword: `SELECT 'keyword', keyword, NULL::varchar, count(*) FROM matches
+		WHERE keyword IS NOT NULL GROUP BY keyword ORDER BY count(*) DESC, keyword LIMIT 10`,
+}
+
+// GetSearchFacets returns the buckets of the requested facets over all the videos matching the search request.
+func (videoRepo videoRepository) GetSearchFacets(ctx context.Context, searchRequest model.SearchVideosRequest, fuzzy bool) (map[string][]model.FacetBucket, error) {
+	facets := make(map[string][]model.FacetBucket, len(searchRequest.Facets))
+	var args []interface{}
+	arg := queryArgs(&args)
+	match, err := videoRepo.searchMatch(ctx, searchRequest, fuzzy, arg)
+	if err != nil {
+		return nil, err
+	}
+	facetSelects := make([]string, 0, len(searchRequest.Facets))
+	for _, facet := range searchRequest.Facets {
+		if _, ok := facets[facet]; ok {
+			continue
+		}
+		facets[facet] = []model.FacetBucket{}
+		facetSelects = append(facetSelects, "("+facetQueries[facet]+")")
+	}
+	// the matches are computed once and shared by all the facets
+	query := fmt.Sprintf("WITH matches AS MATERIALIZED (SELECT channel_id, channel_title, published_at, duration_seconds, keyword FROM videos WHERE %s) %s",
+		match.condition, strings.Join(facetSelects, " UNION ALL "))
+	rows, err := videoRepo.pgxPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var facet string
+		var bucket model.FacetBucket
+		if err = rows.Scan(&facet, &bucket.Value, &bucket.Label, &bucket.Count); err != nil {
+			return nil, err
+		}
+		facets[facet] = append(facets[facet], bucket)
+	}
+	return facets, rows.Err()
+}
+
+// GetSpellingSuggestion returns the given words joined by space, where each word which is not known
+// is replaced with the most similar known word of the stored videos.
+func (videoRepo videoRepository) GetSpellingSuggestion(ctx context.Context, words []string) (string, error) {
+	row := videoRepo.pgxPool.QueryRow(ctx, `SELECT coalesce(string_agg(coalesce(
+			(SELECT word FROM search_words WHERE word = term),
+			(SELECT word FROM search_words WHERE word % term ORDER BY similarity(word, term) DESC, ndoc DESC LIMIT 1),
+			term), ' ' ORDER BY position), '')
+		FROM unnest($1::text[]) WITH ORDINALITY AS terms(term, position)`, words)
+	var suggestion string
+	err := row.Scan(&suggestion)
+	return suggestion, err
+}
+
+// InsertSearchQuery inserts the search query or increments its count if it was already searched.
+func (videoRepo videoRepository) InsertSearchQuery(ctx context.Context, query string) error {
+	_, err := videoRepo.pgxPool.Exec(ctx, "INSERT INTO search_queries (query, last_searched_at) VALUES ($1, $2) ON CONFLICT (query) DO UPDATE SET count = search_queries.count + 1, last_searched_at = EXCLUDED.last_searched_at", query, time.Now().UTC())
+	return err
+}
+
+// SuggestTitles returns the distinct titles of the best matching videos containing the given complete words
+// and a word starting with the given prefix.
+func (videoRepo videoRepository) SuggestTitles(ctx context.Context, words string, prefix string, limit int) ([]string, error) {
+	titles := []string{}
+	// only a few times the limit of top matches are grouped, as re-uploads often share the same title
+	rows, err := videoRepo.pgxPool.Query(ctx, `SELECT title FROM (
+			SELECT title, ts_rank(document_with_weights, query) AS rank, published_at
+			FROM videos, plainto_tsquery($1) && to_tsquery('simple', $2 || ':*') AS query
+			WHERE document_with_weights @@ query ORDER BY rank DESC, published_at DESC LIMIT $3 * 5) AS matches
+		GROUP BY title ORDER BY max(rank) DESC, max(published_at) DESC LIMIT $3`, words, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var title string
+		if err = rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
+
+// SuggestQueries returns the most searched queries starting with the given prefix.
+func (videoRepo videoRepository) SuggestQueries(ctx context.Context, prefix string, limit int) ([]string, error) {
+	queries := []string{}
+	rows, err := videoRepo.pgxPool.Query(ctx, "SELECT query FROM search_queries WHERE query LIKE $1 || '%' ORDER BY count DESC, last_searched_at DESC LIMIT $2", prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var query string
+		if err = rows.Scan(&query); err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	return queries, rows.Err()
+}
+
+// headlineOptions returns the ts_headline options string for the given highlight options.
+// Start and stop selectors are validated by the controller to not contain double quotes.
+func headlineOptions(highlight model.HighlightOptions, maxFragments int) string {
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s"`, highlight.StartSel, highlight.StopSel)
+	if maxFragments > 0 {
+		options += fmt.Sprintf(`, MaxFragments=%d, FragmentDelimiter=" ... "`, maxFragments)
+	}
+	return options
+}
+
 // GetLastPublishedAtDateTime returns the latest published at date time from the database.
-func (videoRepo videoRepository) GetLastPublishedAtDateTime() (time.Time, error) {
-	row := videoRepo.pgxPool.QueryRow(context.Background(), "SELECT published_at FROM videos ORDER BY published_at DESC LIMIT 1")
+func (videoRepo videoRepository) GetLastPublishedAtDateTime(ctx context.Context) (time.Time, error) {
+	row := videoRepo.pgxPool.QueryRow(ctx, "SELECT published_at FROM videos ORDER BY published_at DESC LIMIT 1")
 	var publishedAt time.Time
 	err := row.Scan(&publishedAt)
 	if err != nil && err != pgx.ErrNoRows {
@@ -117,3 +738,23 @@ func (videoRepo videoRepository) GetLastPublishedAtDateTime() (time.Time, error)
 	}
 	return publishedAt, nil
 }
+
+// ReindexVideos rebuilds document_with_weights of all the videos in batches, so that changes of the stopwords
+// are applied to the already indexed videos. It returns the number of reindexed videos.
+func (videoRepo videoRepository) ReindexVideos(ctx context.Context, batchSize int) (int64, error) {
+	var reindexed, lastID int64
+	for {
+		// updating a row fires the tsvupdate trigger, which rebuilds its document_with_weights
+		row := videoRepo.pgxPool.QueryRow(ctx, `WITH batch AS (SELECT id FROM videos WHERE id > $1 ORDER BY id LIMIT $2),
+			updated AS (UPDATE videos SET document_with_weights = videos.document_with_weights FROM batch WHERE videos.id = batch.id RETURNING videos.id)
+			SELECT count(*), coalesce(max(id), 0) FROM updated`, lastID, batchSize)
+		var count int64
+		if err := row.Scan(&count, &lastID); err != nil {
+			return reindexed, err
+		}
+		if count == 0 {
+			return reindexed, nil
+		}
+		reindexed += count
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"strings"
+	"testing"
+)
+
+func TestSearchMatchBindsSearchString(t *testing.T) {
+	tests := []struct {
+		name          string
+		searchRequest model.SearchVideosRequest
+		synonyms      map[string][]string
+		stopwords     map[string]bool
+		fuzzy         bool
+		wantCondition string
+	}{
+		{
+			name:          "plain",
+			searchRequest: model.SearchVideosRequest{SearchString: "cricket highlights"},
+			wantCondition: "document_with_weights @@ plainto_tsquery($1)",
+		},
+		{
+			name:          "language",
+			searchRequest: model.SearchVideosRequest{SearchString: "cricket highlights", Language: "es"},
+			wantCondition: "document_with_weights @@ plainto_tsquery($2::regconfig, $1)",
+		},
+		{
+			name:          "fuzzy",
+			searchRequest: model.SearchVideosRequest{SearchString: "crikcet"},
+			fuzzy:         true,
+			wantCondition: "(document_with_weights @@ plainto_tsquery($1) OR $1 <% title)",
+		},
+		{
+			name:          "synonyms",
+			searchRequest: model.SearchVideosRequest{SearchString: "soccer highlights"},
+			synonyms:      map[string][]string{"soccer": {"football"}},
+			wantCondition: "document_with_weights @@ ((phraseto_tsquery($2) || phraseto_tsquery($3)) && (phraseto_tsquery($4)))",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []interface{}
+			m := newSearchMatch(tt.searchRequest, tt.synonyms, tt.stopwords, tt.fuzzy, queryArgs(&args))
+			if len(args) == 0 || args[0] != tt.searchRequest.SearchString {
+				t.Errorf("args = %v, want the search string first", args)
+			}
+			if m.condition != tt.wantCondition {
+				t.Errorf("condition = %s, want %s", m.condition, tt.wantCondition)
+			}
+		})
+	}
+}
+
+func TestSearchMatchAfterOtherArgs(t *testing.T) {
+	var args []interface{}
+	arg := queryArgs(&args)
+	arg(int64(42))
+	m := newSearchMatch(model.SearchVideosRequest{SearchString: "cricket"}, nil, nil, true, arg)
+	if len(args) != 2 || args[1] != "cricket" {
+		t.Fatalf("args = %v, want the search string after the other args", args)
+	}
+	if strings.Contains(m.condition, "$1") || !strings.Contains(m.condition, "plainto_tsquery($2)") {
+		t.Errorf("condition = %s, want the search string placeholder $2", m.condition)
+	}
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type WebhookRepository interface {
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (model.Webhook, error)
+	InsertWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) (bool, error)
+	InsertWebhookDelivery(ctx context.Context, webhookID int64, event string, payload []byte) (int64, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, deliveryID int64, attempt model.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int, offset int) ([]model.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, webhookID int64, id int64) (model.WebhookDelivery, error)
+	RedeliverWebhookDelivery(ctx context.Context, webhookID int64, id int64) (bool, error)
+}
+
+// webhookColumns are the columns of webhooks table scanned by webhookDest
+const webhookColumns = "id, url, secret, query, language, channel_id, keyword, created_at, updated_at"
+
+// webhookDest returns the scan destinations of webhookColumns for the given webhook
+func webhookDest(webhook *model.Webhook) []interface{} {
+	return []interface{}{&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.Query, &webhook.Filters.Language, &webhook.Filters.ChannelID,
+		&webhook.Filters.Keyword, &webhook.CreatedAt, &webhook.UpdatedAt}
+}
+
+// webhookDeliveryColumns are the columns of webhook_deliveries table scanned by webhookDeliveryDest
+const webhookDeliveryColumns = "id, webhook_id, event, status, attempt_count, next_attempt_at, last_status_code, last_error, created_at, delivered_at"
+
+// webhookDeliveryDest returns the scan destinations of webhookDeliveryColumns for the given delivery
+func webhookDeliveryDest(delivery *model.WebhookDelivery) []interface{} {
+	return []interface{}{&delivery.ID, &delivery.WebhookID, &delivery.Event, &delivery.Status, &delivery.AttemptCount, &delivery.NextAttemptAt,
+		&delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt}
+}
+
+type webhookRepository struct {
+	pgxPool *pgxpool.Pool
+}
+
+func NewWebhookRepo(pgxPool *pgxpool.Pool) WebhookRepository {
+	return webhookRepository{
+		pgxPool: pgxPool,
+	}
+}
+
+// GetWebhooks returns all the webhooks ordered by id.
+func (webhookRepo webhookRepository) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+	rows, err := webhookRepo.pgxPool.Query(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var webhook model.Webhook
+		if err = rows.Scan(webhookDest(&webhook)...); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhook returns the webhook with the given id, pgx.ErrNoRows is returned if it doesn't exist.
+func (webhookRepo webhookRepository) GetWebhook(ctx context.Context, id int64) (model.Webhook, error) {
+	var webhook model.Webhook
+	row := webhookRepo.pgxPool.QueryRow(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id)
+	err := row.Scan(webhookDest(&webhook)...)
+	return webhook, err
+}
+
+// InsertWebhook inserts the webhook and returns it with its id.
+func (webhookRepo webhookRepository) InsertWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	currentTime := time.Now().UTC()
+	webhook.CreatedAt, webhook.UpdatedAt = currentTime, currentTime
+	row := webhookRepo.pgxPool.QueryRow(ctx, "INSERT INTO webhooks (url, secret, query, language, channel_id, keyword, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		webhook.URL, webhook.Secret, webhook.Query, webhook.Filters.Language, webhook.Filters.ChannelID, webhook.Filters.Keyword, webhook.CreatedAt, webhook.UpdatedAt)
+	err := row.Scan(&webhook.ID)
+	return webhook, err
+}
+
+// UpdateWebhook updates the webhook, pgx.ErrNoRows is returned if it doesn't exist.
+func (webhookRepo webhookRepository) UpdateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	webhook.UpdatedAt = time.Now().UTC()
+	row := webhookRepo.pgxPool.QueryRow(ctx, "UPDATE webhooks SET url = $1, secret = $2, query = $3, language = $4, channel_id = $5, keyword = $6, updated_at = $7 WHERE id = $8 RETURNING created_at",
+		webhook.URL, webhook.Secret, webhook.Query, webhook.Filters.Language, webhook.Filters.ChannelID, webhook.Filters.Keyword, webhook.UpdatedAt, webhook.ID)
+	err := row.Scan(&webhook.CreatedAt)
+	return webhook, err
+}
+
+// DeleteWebhook deletes the webhook along with its deliveries and returns whether it existed.
+func (webhookRepo webhookRepository) DeleteWebhook(ctx context.Context, id int64) (bool, error) {
+	tag, err := webhookRepo.pgxPool.Exec(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	return tag.RowsAffected() > 0, err
+}
+
+// InsertWebhookDelivery inserts a pending delivery of the payload to the webhook, which is due immediately, and returns its id.
+func (webhookRepo webhookRepository) InsertWebhookDelivery(ctx context.Context, webhookID int64, event string, payload []byte) (int64, error) {
+	currentTime := time.Now().UTC()
+	row := webhookRepo.pgxPool.QueryRow(ctx, "INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $5) RETURNING id",
+		webhookID, event, payload, model.WebhookDeliveryPending, currentTime)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+// ClaimDueWebhookDeliveries returns the pending deliveries which are due along with their payload, and postpones their
+// next attempt by the lease, so that they are not claimed again while they are being attempted.
+func (webhookRepo webhookRepository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	currentTime := time.Now().UTC()
+	rows, err := webhookRepo.pgxPool.Query(ctx, `UPDATE webhook_deliveries SET next_attempt_at = $1 WHERE id IN (
+			SELECT id FROM webhook_deliveries WHERE status = $2 AND next_attempt_at <= $3 ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED)
+		RETURNING `+webhookDeliveryColumns+`, payload`, currentTime.Add(lease), model.WebhookDeliveryPending, currentTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		if err = rows.Scan(append(webhookDeliveryDest(&delivery), &delivery.Payload)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordWebhookDeliveryAttempt logs the attempt of the delivery and updates its status, along with the next attempt time
+// of a delivery which is still pending.
+func (webhookRepo webhookRepository) RecordWebhookDeliveryAttempt(ctx context.Context, deliveryID int64, attempt model.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	var deliveredAt *time.Time
+	if status == model.WebhookDeliverySucceeded {
+		deliveredAt = &attempt.AttemptedAt
+	}
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms) VALUES ($1, $2, $3, $4, $5)",
+		deliveryID, attempt.AttemptedAt, attempt.StatusCode, attempt.Error, attempt.DurationMs)
+	batch.Queue("UPDATE webhook_deliveries SET status = $1, attempt_count = attempt_count + 1, next_attempt_at = $2, last_status_code = $3, last_error = $4, delivered_at = $5 WHERE id = $6",
+		status, nextAttemptAt, attempt.StatusCode, attempt.Error, deliveredAt, deliveryID)
+	// the batch is sent in an implicit transaction, so the attempt is logged along with the delivery update
+	return webhookRepo.pgxPool.SendBatch(ctx, batch).Close()
+}
+
+// GetWebhookDeliveries returns the deliveries of the webhook, latest first, without their payload and attempts.
+func (webhookRepo webhookRepository) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int, offset int) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	rows, err := webhookRepo.pgxPool.Query(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3",
+		webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		if err = rows.Scan(webhookDeliveryDest(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// GetWebhookDelivery returns the delivery of the webhook with its payload and attempts, pgx.ErrNoRows is returned if it doesn't exist.
+func (webhookRepo webhookRepository) GetWebhookDelivery(ctx context.Context, webhookID int64, id int64) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	row := webhookRepo.pgxPool.QueryRow(ctx, "SELECT "+webhookDeliveryColumns+", payload FROM webhook_deliveries WHERE webhook_id = $1 AND id = $2", webhookID, id)
+	if err := row.Scan(append(webhookDeliveryDest(&delivery), &delivery.Payload)...); err != nil {
+		return delivery, err
+	}
+	rows, err := webhookRepo.pgxPool.Query(ctx, "SELECT attempted_at, status_code, error, duration_ms FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY attempted_at, id", id)
+	if err != nil {
+		return delivery, err
+	}
+	defer rows.Close()
+	delivery.Attempts = []model.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt model.WebhookDeliveryAttempt
+		if err = rows.Scan(&attempt.AttemptedAt, &attempt.StatusCode, &attempt.Error, &attempt.DurationMs); err != nil {
+			return delivery, err
+		}
+		delivery.Attempts = append(delivery.Attempts, attempt)
+	}
+	return delivery, rows.Err()
+}
+
+// RedeliverWebhookDelivery makes the delivery of the webhook pending and due immediately with a fresh budget of attempts,
+// keeping the log of its previous attempts. It returns whether the delivery exists.
+func (webhookRepo webhookRepository) RedeliverWebhookDelivery(ctx context.Context, webhookID int64, id int64) (bool, error) {
+	tag, err := webhookRepo.pgxPool.Exec(ctx, "UPDATE webhook_deliveries SET status = $1, attempt_count = 0, next_attempt_at = $2, delivered_at = NULL WHERE webhook_id = $3 AND id = $4",
+		model.WebhookDeliveryPending, time.Now().UTC(), webhookID, id)
+	return tag.RowsAffected() > 0, err
+}
@@ -1,18 +1,49 @@
 package route
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/Gohelraj/youtube-search-api/api/controller"
+	"github.com/Gohelraj/youtube-search-api/api/graphql"
+	"github.com/Gohelraj/youtube-search-api/api/middleware"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/openapi"
 	"github.com/Gohelraj/youtube-search-api/api/repository"
 	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/cron_job"
+	"github.com/Gohelraj/youtube-search-api/pkg/health"
+	"github.com/Gohelraj/youtube-search-api/pkg/metrics"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"github.com/Gohelraj/youtube-search-api/pkg/tracing"
+	"github.com/Gohelraj/youtube-search-api/pkg/youtube"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
-// InitializeRouter initialize all API routes
-func InitializeRouter(pgxPool *pgxpool.Pool) *gin.Engine {
-	// Default gin router with the Logger and Recovery middleware already attached.
-	router := gin.Default()
+// InitializeRouter initialize all API routes, the video and API client services and the rate limiter are shared with
+// the gRPC server. The streams of the videos end once stopping is closed.
+func InitializeRouter(pgxPool *pgxpool.Pool, videoService service.VideoService, apiClientService service.APIClientService, limiter ratelimit.Limiter, stopping <-chan struct{}) *gin.Engine {
+	// the requests are logged with their request id as structured logs, and their panics are recovered inside the
+	// logging and metrics middleware so that they are recorded as 500 responses
+	router := gin.New()
+	router.Use(middleware.RequestID(), otelgin.Middleware(tracing.ServiceName), middleware.Logger(), middleware.Metrics(), middleware.Recovery())
+	// the client IP of the requests, which the rate limits are kept by, is only taken from the X-Forwarded-For header
+	// of the trusted proxies, so that clients can't spoof it
+	if err := router.SetTrustedProxies(config.Conf.RateLimit.TrustedProxies); err != nil {
+		// the trusted proxies are validated when the config is loaded
+		panic(fmt.Sprintf("setting trusted proxies: %v", err))
+	}
+	router.NoRoute(func(c *gin.Context) {
+		er.SendError(c, er.ErrRouteNotFound)
+	})
 
 	// Health check endpoint for the API server to check if it is running
 	router.GET("/health-check", func(c *gin.Context) {
@@ -20,13 +51,130 @@ func InitializeRouter(pgxPool *pgxpool.Pool) *gin.Engine {
 		return
 	})
 
-	videoRepository := repository.NewVideoRepo(pgxPool)
-	videoService := service.NewVideoService(videoRepository)
-	videoController := controller.NewVideoController(videoService)
+	// liveness and readiness probes of the orchestrator
+	healthController := controller.NewHealthController(readinessChecks(pgxPool))
+	router.GET("/livez", healthController.Livez)
+	router.GET("/readyz", healthController.Readyz)
+
+	// OpenAPI document of the routes and its Swagger UI
+	router.GET("/openapi.json", openapi.GetSpec)
+	router.GET("/docs", openapi.GetDocs)
+
+	// Prometheus metrics of the API, the ingestion, the queue and the connection pool
+	metrics.RegisterPool(pgxPool)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	apiClientController := controller.NewAPIClientController(apiClientService)
+
+	// the routes of each group require an API key of a client having the scope of the group
+	read := router.Group("", middleware.Authenticate(apiClientService, model.ScopeRead))
+	search := router.Group("", middleware.Authenticate(apiClientService, model.ScopeSearch))
+	admin := router.Group("/admin", middleware.Authenticate(apiClientService, model.ScopeAdmin))
+
+	videoController := controller.NewVideoController(videoService, stopping)
+
+	// the expensive search routes have a separate budget, so that searching doesn't starve listing the videos
+	videosLimit := middleware.RateLimit(limiter, middleware.VideosBudget, middleware.BudgetLimit(middleware.VideosBudget))
+	searchLimit := middleware.RateLimit(limiter, middleware.SearchBudget, middleware.BudgetLimit(middleware.SearchBudget))
 
 	// Videos API routes
-	router.GET("/videos", videoController.GetVideos)
-	router.POST("/videos/search", videoController.SearchVideos)
+	read.GET("/videos", videosLimit, videoController.GetVideos)
+	read.GET("/videos/export", videosLimit, videoController.ExportVideos)
+	search.POST("/videos/search", searchLimit, videoController.SearchVideos)
+	search.GET("/videos/suggest", videosLimit, videoController.SuggestVideos)
+	read.GET("/videos/stream", videosLimit, videoController.StreamVideos)
+	read.GET("/videos/feed.atom", videosLimit, videoController.GetVideosFeed)
+	read.GET("/videos/feed.rss", videosLimit, videoController.GetVideosFeed)
+	search.GET("/videos/search/feed.atom", searchLimit, videoController.SearchVideosFeed)
+	search.GET("/videos/search/feed.rss", searchLimit, videoController.SearchVideosFeed)
+	read.GET("/videos/:youtubeId/similar", videosLimit, videoController.GetSimilarVideos)
+
+	// GraphQL API of the videos, their channels and the search, whose search field requires the search scope and
+	// takes a token of the search budget too
+	schema, err := graphql.NewSchema(videoService)
+	if err != nil {
+		// the schema is static, so it only fails to be created when it is invalid
+		panic(fmt.Sprintf("creating GraphQL schema: %v", err))
+	}
+	graphQLController := controller.NewGraphQLController(schema, func(c *gin.Context) error {
+		return middleware.TakeRateLimit(c, limiter, middleware.SearchBudget, middleware.BudgetLimit(middleware.SearchBudget))
+	})
+	read.GET("/graphql", videosLimit, graphQLController.Query)
+	read.POST("/graphql", videosLimit, graphQLController.Query)
+
+	savedSearchRepository := repository.NewSavedSearchRepo(pgxPool)
+	savedSearchService := service.NewSavedSearchService(savedSearchRepository)
+	savedSearchController := controller.NewSavedSearchController(savedSearchService)
+
+	// Saved searches API routes
+	search.GET("/saved-searches", savedSearchController.GetSavedSearches)
+	search.POST("/saved-searches", savedSearchController.CreateSavedSearch)
+	search.GET("/saved-searches/:id", savedSearchController.GetSavedSearch)
+	search.PUT("/saved-searches/:id", savedSearchController.UpdateSavedSearch)
+	search.DELETE("/saved-searches/:id", savedSearchController.DeleteSavedSearch)
+	search.GET("/saved-searches/:id/new", savedSearchController.GetNewVideos)
+
+	searchDictionaryRepository := repository.NewSearchDictionaryRepo(pgxPool)
+	searchDictionaryService := service.NewSearchDictionaryService(searchDictionaryRepository)
+	searchDictionaryController := controller.NewSearchDictionaryController(searchDictionaryService)
+
+	webhookRepository := repository.NewWebhookRepo(pgxPool)
+	webhookService := service.NewWebhookService(webhookRepository)
+	webhookController := controller.NewWebhookController(webhookService)
+
+	videoImportService := service.NewVideoImportService(repository.NewVideoRepo(pgxPool), savedSearchRepository, webhookRepository)
+	videoImportController := controller.NewVideoImportController(videoImportService)
+
+	// Admin API routes
+	admin.GET("/api-clients", apiClientController.GetAPIClients)
+	admin.POST("/api-clients", apiClientController.CreateAPIClient)
+	admin.GET("/api-clients/:id", apiClientController.GetAPIClient)
+	admin.POST("/api-clients/:id/rotate", apiClientController.RotateAPIClientKey)
+	admin.DELETE("/api-clients/:id", apiClientController.RevokeAPIClient)
+	admin.POST("/videos/import", videoImportController.ImportVideos)
+	admin.GET("/search/synonyms", searchDictionaryController.GetSynonyms)
+	admin.POST("/search/synonyms", searchDictionaryController.CreateSynonym)
+	admin.GET("/search/synonyms/:id", searchDictionaryController.GetSynonym)
+	admin.PUT("/search/synonyms/:id", searchDictionaryController.UpdateSynonym)
+	admin.DELETE("/search/synonyms/:id", searchDictionaryController.DeleteSynonym)
+	admin.GET("/search/stopwords", searchDictionaryController.GetStopwords)
+	admin.POST("/search/stopwords", searchDictionaryController.CreateStopword)
+	admin.DELETE("/search/stopwords/:word", searchDictionaryController.DeleteStopword)
+	admin.GET("/webhooks", webhookController.GetWebhooks)
+	admin.POST("/webhooks", webhookController.CreateWebhook)
+	admin.GET("/webhooks/:id", webhookController.GetWebhook)
+	admin.PUT("/webhooks/:id", webhookController.UpdateWebhook)
+	admin.DELETE("/webhooks/:id", webhookController.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", webhookController.GetDeliveries)
+	admin.GET("/webhooks/:id/deliveries/:deliveryId", webhookController.GetDelivery)
+	admin.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookController.Redeliver)
 
 	return router
 }
+
+// readinessChecks returns the checks of the dependencies which the server needs to serve the requests and ingest the videos
+func readinessChecks(pgxPool *pgxpool.Pool) map[string]health.Check {
+	return map[string]health.Check{
+		"postgres": func(ctx context.Context) error {
+			if err := pgxPool.Ping(ctx); err != nil {
+				// the error is logged rather than shown, as it has the address of the database
+				slog.ErrorContext(ctx, "Error pinging postgres", "err", err)
+				return errors.New("ping failed")
+			}
+			return nil
+		},
+		"amqp": func(context.Context) error {
+			return youtube.ConsumerStatus()
+		},
+		"cron": func(context.Context) error {
+			return cron_job.Status()
+		},
+		"ingestion": func(context.Context) error {
+			age := time.Since(youtube.LastIngestionAt())
+			if age > config.Conf.Health.IngestionMaxAge {
+				return fmt.Errorf("last successful ingestion %s ago", age.Round(time.Second))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Gohelraj/youtube-search-api/api/openapi"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	"github.com/Gohelraj/youtube-search-api/pkg/notify"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// pathParam matches the path params of the gin routes, e.g. ":id"
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// the pool connects lazily, so the routes are registered without a database
+	conf, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 user=test dbname=test")
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	conf.LazyConnect = true
+	pgxPool, err := pgxpool.ConnectConfig(context.Background(), conf)
+	if err != nil {
+		t.Fatalf("ConnectConfig() error = %v", err)
+	}
+	defer pgxPool.Close()
+
+	routes := make(map[string]bool)
+	videoRepository := repository.NewVideoRepo(pgxPool)
+	videoService := service.NewVideoService(videoRepository, notify.NewListener(pgxPool, repository.VideosInsertedChannel),
+		service.NewSearchQueryRecorder(videoRepository))
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepo(pgxPool))
+	for _, route := range InitializeRouter(pgxPool, videoService, apiClientService, ratelimit.NewMemoryLimiter(), nil).Routes() {
+		routes[route.Method+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	operations := make(map[string]bool)
+	for path, item := range spec.Paths {
+		for method := range item {
+			operations[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var missing, stale []string
+	for route := range routes {
+		if !operations[route] {
+			missing = append(missing, route)
+		}
+	}
+	for operation := range operations {
+		if !routes[operation] {
+			stale = append(stale, operation)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	for _, route := range missing {
+		t.Errorf("route %s is not described by the spec", route)
+	}
+	for _, operation := range stale {
+		t.Errorf("spec describes %s, which is not a route", operation)
+	}
+}
+
+func TestSpecReferences(t *testing.T) {
+	var spec struct {
+		Components map[string]map[string]json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	refs := regexp.MustCompile(`"\$ref":\s*"#/components/(\w+)/(\w+)"`).FindAllStringSubmatch(string(openapi.Spec), -1)
+	if len(refs) == 0 {
+		t.Fatalf("spec has no references")
+	}
+	for _, ref := range refs {
+		if _, ok := spec.Components[ref[1]][ref[2]]; !ok {
+			t.Errorf("reference %s/%s is not defined in the components", ref[1], ref[2])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/apikey"
+	"github.com/jackc/pgx/v4"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// lastUsedInterval is the interval at which the last use of a client is recorded, so that every request doesn't write it
+const lastUsedInterval = time.Minute
+
+type APIClientService interface {
+	GetAPIClients(ctx context.Context) ([]model.APIClient, error)
+	GetAPIClient(ctx context.Context, id int64) (model.APIClient, error)
+	CreateAPIClient(ctx context.Context, client model.APIClient) (model.APIClient, error)
+	RotateAPIClientKey(ctx context.Context, id int64) (model.APIClient, error)
+	RevokeAPIClient(ctx context.Context, id int64) (model.APIClient, error)
+	Authenticate(ctx context.Context, key string) (model.APIClient, error)
+}
+
+type apiClientService struct {
+	apiClientRepository repository.APIClientRepository
+}
+
+func NewAPIClientService(r repository.APIClientRepository) APIClientService {
+	return apiClientService{
+		apiClientRepository: r,
+	}
+}
+
+func (s apiClientService) GetAPIClients(ctx context.Context) ([]model.APIClient, error) {
+	return s.apiClientRepository.GetAPIClients(ctx)
+}
+
+func (s apiClientService) GetAPIClient(ctx context.Context, id int64) (model.APIClient, error) {
+	client, err := s.apiClientRepository.GetAPIClient(ctx, id)
+	if err == pgx.ErrNoRows {
+		return client, er.ErrAPIClientNotFound
+	}
+	return client, err
+}
+
+// CreateAPIClient issues a key to a new client, the key is returned only in the created client
+func (s apiClientService) CreateAPIClient(ctx context.Context, client model.APIClient) (model.APIClient, error) {
+	if err := normalizeAPIClient(&client); err != nil {
+		return client, err
+	}
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		return client, err
+	}
+	client.KeyPrefix, client.KeyHash = prefix, hash
+	client, err = s.apiClientRepository.InsertAPIClient(ctx, client)
+	client.Key = key
+	return client, err
+}
+
+// RotateAPIClientKey issues a new key to the client, after which its previous key can't authenticate anymore
+func (s apiClientService) RotateAPIClientKey(ctx context.Context, id int64) (model.APIClient, error) {
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		return model.APIClient{}, err
+	}
+	client, err := s.apiClientRepository.RotateAPIClientKey(ctx, id, prefix, hash)
+	if err == pgx.ErrNoRows {
+		// the client is either revoked or doesn't exist
+		if _, err = s.GetAPIClient(ctx, id); err != nil {
+			return client, err
+		}
+		return client, er.ErrAPIClientRevoked
+	}
+	client.Key = key
+	return client, err
+}
+
+// RevokeAPIClient revokes the client, revoking a client which is already revoked has no effect
+func (s apiClientService) RevokeAPIClient(ctx context.Context, id int64) (model.APIClient, error) {
+	client, err := s.apiClientRepository.RevokeAPIClient(ctx, id)
+	if err == pgx.ErrNoRows {
+		return client, er.ErrAPIClientNotFound
+	}
+	return client, err
+}
+
+// Authenticate returns the client which isn't revoked of the given key, er.ErrUnauthorized is returned if there is none
+func (s apiClientService) Authenticate(ctx context.Context, key string) (model.APIClient, error) {
+	if !apikey.IsWellFormed(key) {
+		return model.APIClient{}, er.ErrUnauthorized
+	}
+	client, err := s.apiClientRepository.GetActiveAPIClientByKeyHash(ctx, apikey.Hash(key))
+	if err == pgx.ErrNoRows {
+		return client, er.ErrUnauthorized
+	}
+	if err != nil {
+		return client, err
+	}
+	currentTime := time.Now().UTC()
+	if client.LastUsedAt == nil || currentTime.Sub(*client.LastUsedAt) >= lastUsedInterval {
+		// the last use is recorded after the response is sent, so it isn't canceled with the request
+		ctx := context.WithoutCancel(ctx)
+		go func() {
+			if err := s.apiClientRepository.TouchAPIClient(ctx, client.ID, currentTime); err != nil {
+				slog.ErrorContext(ctx, "Error recording the last use of API client", "api_client_id", client.ID, "err", err)
+			}
+		}()
+	}
+	return client, nil
+}
+
+// apiClientScopes are the scopes which can be granted to the clients
+var apiClientScopes = map[string]bool{model.ScopeRead: true, model.ScopeSearch: true, model.ScopeAdmin: true}
+
+// normalizeAPIClient trims the name of the client and removes its duplicate scopes, and validates them
+func normalizeAPIClient(client *model.APIClient) error {
+	client.Name = strings.TrimSpace(client.Name)
+	if client.Name == "" || utf8.RuneCountInString(client.Name) > 100 {
+		return er.ErrInvalidAPIClientName
+	}
+	scopes := []string{}
+	granted := map[string]bool{}
+	for _, scope := range client.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !apiClientScopes[scope] {
+			return er.ErrInvalidAPIClientScopes
+		}
+		if !granted[scope] {
+			granted[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return er.ErrInvalidAPIClientScopes
+	}
+	client.Scopes = scopes
+	return nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/utils"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+// SavedSearchService manages the saved searches, the owner given to its methods restricts them to the saved searches
+// of the owner, and the saved searches of all the owners are accessible when it is empty
+type SavedSearchService interface {
+	GetSavedSearches(ctx context.Context, owner string) ([]model.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id int64, owner string) (model.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, savedSearch model.SavedSearch) (model.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, savedSearch model.SavedSearch, owner string) (model.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id int64, owner string) error
+	GetNewVideos(ctx context.Context, id int64, owner string, limit int) ([]model.VideoMetadata, error)
+}
+
+type savedSearchService struct {
+	savedSearchRepository repository.SavedSearchRepository
+}
+
+func NewSavedSearchService(r repository.SavedSearchRepository) SavedSearchService {
+	return savedSearchService{
+		savedSearchRepository: r,
+	}
+}
+
+func (s savedSearchService) GetSavedSearches(ctx context.Context, owner string) ([]model.SavedSearch, error) {
+	return s.savedSearchRepository.GetSavedSearches(ctx, strings.TrimSpace(owner))
+}
+
+func (s savedSearchService) GetSavedSearch(ctx context.Context, id int64, owner string) (model.SavedSearch, error) {
+	savedSearch, err := s.savedSearchRepository.GetSavedSearch(ctx, id, owner)
+	if err == pgx.ErrNoRows {
+		return savedSearch, er.ErrSavedSearchNotFound
+	}
+	return savedSearch, err
+}
+
+func (s savedSearchService) CreateSavedSearch(ctx context.Context, savedSearch model.SavedSearch) (model.SavedSearch, error) {
+	if err := normalizeSavedSearch(&savedSearch); err != nil {
+		return savedSearch, err
+	}
+	return s.savedSearchRepository.InsertSavedSearch(ctx, savedSearch)
+}
+
+func (s savedSearchService) UpdateSavedSearch(ctx context.Context, savedSearch model.SavedSearch, owner string) (model.SavedSearch, error) {
+	if err := normalizeSavedSearch(&savedSearch); err != nil {
+		return savedSearch, err
+	}
+	savedSearch, err := s.savedSearchRepository.UpdateSavedSearch(ctx, savedSearch, owner)
+	if err == pgx.ErrNoRows {
+		return savedSearch, er.ErrSavedSearchNotFound
+	}
+	return savedSearch, err
+}
+
+func (s savedSearchService) DeleteSavedSearch(ctx context.Context, id int64, owner string) error {
+	deleted, err := s.savedSearchRepository.DeleteSavedSearch(ctx, id, owner)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return er.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// GetNewVideos returns the videos matching the saved search which were not returned before, the saved search of
+// another owner is not found so that its new videos are not marked as seen
+func (s savedSearchService) GetNewVideos(ctx context.Context, id int64, owner string, limit int) ([]model.VideoMetadata, error) {
+	if _, err := s.GetSavedSearch(ctx, id, owner); err != nil {
+		return nil, err
+	}
+	return s.savedSearchRepository.GetNewSavedSearchVideos(ctx, id, limit)
+}
+
+// normalizeSavedSearch trims the fields of the saved search and validates them
+func normalizeSavedSearch(savedSearch *model.SavedSearch) error {
+	savedSearch.Name = strings.TrimSpace(savedSearch.Name)
+	if savedSearch.Name == "" || len(savedSearch.Name) > 100 {
+		return er.ErrInvalidSavedSearchName
+	}
+	savedSearch.Query = strings.TrimSpace(savedSearch.Query)
+	if savedSearch.Query == "" || len(savedSearch.Query) > 200 {
+		return er.ErrInvalidSavedSearchQuery
+	}
+	savedSearch.Owner = strings.TrimSpace(savedSearch.Owner)
+	if savedSearch.Owner == "" || len(savedSearch.Owner) > 100 {
+		return er.ErrInvalidSavedSearchOwner
+	}
+	return normalizeVideoFilters(&savedSearch.Filters)
+}
+
+// normalizeVideoFilters trims the filters and validates them, empty filters are unset
+func normalizeVideoFilters(filters *model.VideoFilters) error {
+	filters.Language, filters.ChannelID, filters.Keyword = trimToNil(filters.Language), trimToNil(filters.ChannelID), trimToNil(filters.Keyword)
+	if filters.Language != nil {
+		if _, ok := utils.TextSearchConfig(*filters.Language); !ok {
+			return er.ErrUnsupportedLanguage
+		}
+	}
+	if filters.ChannelID != nil && len(*filters.ChannelID) > 50 || filters.Keyword != nil && len(*filters.Keyword) > 100 {
+		return er.ErrInvalidVideoFilters
+	}
+	return nil
+}
+
+// trimToNil returns the trimmed value, or nil if it is nil or blank
+func trimToNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"testing"
+)
+
+// fakeSavedSearchRepository stores the saved searches by id, and records whether new videos were requested
+type fakeSavedSearchRepository struct {
+	repository.SavedSearchRepository
+	savedSearches    map[int64]model.SavedSearch
+	newVideosFetched bool
+}
+
+func (f *fakeSavedSearchRepository) GetSavedSearch(_ context.Context, id int64, owner string) (model.SavedSearch, error) {
+	savedSearch, ok := f.savedSearches[id]
+	if !ok || owner != "" && savedSearch.Owner != owner {
+		return model.SavedSearch{}, pgx.ErrNoRows
+	}
+	return savedSearch, nil
+}
+
+func (f *fakeSavedSearchRepository) UpdateSavedSearch(ctx context.Context, savedSearch model.SavedSearch, owner string) (model.SavedSearch, error) {
+	if _, err := f.GetSavedSearch(ctx, savedSearch.ID, owner); err != nil {
+		return savedSearch, err
+	}
+	f.savedSearches[savedSearch.ID] = savedSearch
+	return savedSearch, nil
+}
+
+func (f *fakeSavedSearchRepository) DeleteSavedSearch(ctx context.Context, id int64, owner string) (bool, error) {
+	if _, err := f.GetSavedSearch(ctx, id, owner); err != nil {
+		return false, nil
+	}
+	delete(f.savedSearches, id)
+	return true, nil
+}
+
+func (f *fakeSavedSearchRepository) GetNewSavedSearchVideos(context.Context, int64, int) ([]model.VideoMetadata, error) {
+	f.newVideosFetched = true
+	return []model.VideoMetadata{}, nil
+}
+
+func TestSavedSearchNotFound(t *testing.T) {
+	savedSearchRepository := &fakeSavedSearchRepository{savedSearches: map[int64]model.SavedSearch{
+		1: {ID: 1, Name: "IPL", Query: "ipl", Owner: "api-client:1"},
+	}}
+	s := NewSavedSearchService(savedSearchRepository)
+	ctx := context.Background()
+
+	if savedSearch, err := s.GetSavedSearch(ctx, 1, "api-client:1"); err != nil || savedSearch.Name != "IPL" {
+		t.Errorf("GetSavedSearch() = %+v, %v, want the saved search of its owner", savedSearch, err)
+	}
+	if _, err := s.GetSavedSearch(ctx, 1, ""); err != nil {
+		t.Errorf("GetSavedSearch() error = %v, want the saved search of any owner without an owner", err)
+	}
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"get missing", func() error { _, err := s.GetSavedSearch(ctx, 2, ""); return err }},
+		{"get of another owner", func() error { _, err := s.GetSavedSearch(ctx, 1, "api-client:2"); return err }},
+		{"update of another owner", func() error {
+			_, err := s.UpdateSavedSearch(ctx, model.SavedSearch{ID: 1, Name: "IPL", Query: "ipl", Owner: "api-client:2"}, "api-client:2")
+			return err
+		}},
+		{"delete of another owner", func() error { return s.DeleteSavedSearch(ctx, 1, "api-client:2") }},
+		{"new videos of another owner", func() error { _, err := s.GetNewVideos(ctx, 1, "api-client:2", 10); return err }},
+	}
+	for _, test := range tests {
+		if err := test.call(); err != er.ErrSavedSearchNotFound {
+			t.Errorf("%s: error = %v, want %v", test.name, err, er.ErrSavedSearchNotFound)
+		}
+	}
+	if savedSearchRepository.newVideosFetched {
+		t.Errorf("new videos of another owner were fetched and marked as seen")
+	}
+	if _, ok := savedSearchRepository.savedSearches[1]; !ok {
+		t.Errorf("saved search of another owner was deleted")
+	}
+}
+
+func TestNormalizeSavedSearch(t *testing.T) {
+	language, blank := " en ", " "
+	savedSearch := model.SavedSearch{Name: " IPL ", Query: " ipl final ", Owner: " analyst ", Filters: model.VideoFilters{Language: &language, Keyword: &blank}}
+	if err := normalizeSavedSearch(&savedSearch); err != nil {
+		t.Fatalf("normalizeSavedSearch() error = %v", err)
+	}
+	if savedSearch.Name != "IPL" || savedSearch.Query != "ipl final" || savedSearch.Owner != "analyst" ||
+		savedSearch.Filters.Language == nil || *savedSearch.Filters.Language != "en" || savedSearch.Filters.Keyword != nil {
+		t.Errorf("saved search = %+v, want the trimmed fields and the blank filters unset", savedSearch)
+	}
+
+	klingon, longChannelID := "klingon", strings.Repeat("c", 51)
+	tests := []struct {
+		name        string
+		savedSearch model.SavedSearch
+		want        error
+	}{
+		{"blank name", model.SavedSearch{Name: " ", Query: "ipl", Owner: "analyst"}, er.ErrInvalidSavedSearchName},
+		{"long query", model.SavedSearch{Name: "IPL", Query: strings.Repeat("q", 201), Owner: "analyst"}, er.ErrInvalidSavedSearchQuery},
+		{"missing owner", model.SavedSearch{Name: "IPL", Query: "ipl"}, er.ErrInvalidSavedSearchOwner},
+		{"unsupported language", model.SavedSearch{Name: "IPL", Query: "ipl", Owner: "analyst", Filters: model.VideoFilters{Language: &klingon}}, er.ErrUnsupportedLanguage},
+		{"long channel id", model.SavedSearch{Name: "IPL", Query: "ipl", Owner: "analyst", Filters: model.VideoFilters{ChannelID: &longChannelID}}, er.ErrInvalidVideoFilters},
+	}
+	for _, test := range tests {
+		if err := normalizeSavedSearch(&test.savedSearch); err != test.want {
+			t.Errorf("%s: normalizeSavedSearch() = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
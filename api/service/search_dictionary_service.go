@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/utils"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+type SearchDictionaryService interface {
+	GetSynonyms(ctx context.Context) ([]model.SearchSynonym, error)
+	GetSynonym(ctx context.Context, id int64) (model.SearchSynonym, error)
+	CreateSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error)
+	UpdateSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error)
+	DeleteSynonym(ctx context.Context, id int64) error
+	GetStopwords(ctx context.Context) ([]model.SearchStopword, error)
+	CreateStopword(ctx context.Context, word string) (model.SearchStopword, error)
+	DeleteStopword(ctx context.Context, word string) error
+}
+
+type searchDictionaryService struct {
+	searchDictionaryRepository repository.SearchDictionaryRepository
+}
+
+func NewSearchDictionaryService(r repository.SearchDictionaryRepository) SearchDictionaryService {
+	return searchDictionaryService{
+		searchDictionaryRepository: r,
+	}
+}
+
+func (s searchDictionaryService) GetSynonyms(ctx context.Context) ([]model.SearchSynonym, error) {
+	return s.searchDictionaryRepository.GetSynonyms(ctx)
+}
+
+func (s searchDictionaryService) GetSynonym(ctx context.Context, id int64) (model.SearchSynonym, error) {
+	synonym, err := s.searchDictionaryRepository.GetSynonym(ctx, id)
+	if err == pgx.ErrNoRows {
+		return synonym, er.ErrSynonymNotFound
+	}
+	return synonym, err
+}
+
+func (s searchDictionaryService) CreateSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error) {
+	if err := normalizeSynonym(&synonym); err != nil {
+		return synonym, err
+	}
+	synonym, err := s.searchDictionaryRepository.InsertSynonym(ctx, synonym)
+	return synonym, synonymError(err)
+}
+
+func (s searchDictionaryService) UpdateSynonym(ctx context.Context, synonym model.SearchSynonym) (model.SearchSynonym, error) {
+	if err := normalizeSynonym(&synonym); err != nil {
+		return synonym, err
+	}
+	synonym, err := s.searchDictionaryRepository.UpdateSynonym(ctx, synonym)
+	return synonym, synonymError(err)
+}
+
+func (s searchDictionaryService) DeleteSynonym(ctx context.Context, id int64) error {
+	deleted, err := s.searchDictionaryRepository.DeleteSynonym(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return er.ErrSynonymNotFound
+	}
+	return nil
+}
+
+func (s searchDictionaryService) GetStopwords(ctx context.Context) ([]model.SearchStopword, error) {
+	return s.searchDictionaryRepository.GetStopwords(ctx)
+}
+
+func (s searchDictionaryService) CreateStopword(ctx context.Context, word string) (model.SearchStopword, error) {
+	words := utils.SplitWords(word)
+	// stopwords are matched against the words of search strings, so they must be a single word
+	if len(words) != 1 || len(words[0]) > 100 {
+		return model.SearchStopword{}, er.ErrInvalidStopword
+	}
+	return s.searchDictionaryRepository.InsertStopword(ctx, words[0])
+}
+
+func (s searchDictionaryService) DeleteStopword(ctx context.Context, word string) error {
+	deleted, err := s.searchDictionaryRepository.DeleteStopword(ctx, strings.ToLower(word))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return er.ErrStopwordNotFound
+	}
+	return nil
+}
+
+// normalizeSynonym normalizes the term and synonyms the same way as search strings are, so that they can be matched
+func normalizeSynonym(synonym *model.SearchSynonym) error {
+	synonym.Term = strings.Join(utils.SplitWords(synonym.Term), " ")
+	termWords := len(utils.SplitWords(synonym.Term))
+	if termWords == 0 || termWords > utils.MaxSynonymTermWords || len(synonym.Term) > 100 {
+		return er.ErrInvalidSynonymTerm
+	}
+	synonyms := make([]string, 0, len(synonym.Synonyms))
+	for _, s := range synonym.Synonyms {
+		s = strings.Join(utils.SplitWords(s), " ")
+		if s == "" || len(s) > 100 {
+			return er.ErrInvalidSynonyms
+		}
+		if s != synonym.Term && utils.GetIndexOf(s, synonyms) == -1 {
+			synonyms = append(synonyms, s)
+		}
+	}
+	if len(synonyms) == 0 {
+		return er.ErrInvalidSynonyms
+	}
+	synonym.Synonyms = synonyms
+	return nil
+}
+
+// synonymError maps the repository errors of writing a synonym to API errors
+func synonymError(err error) error {
+	var pgErr *pgconn.PgError
+	// 23505 is the unique_violation error code of postgres
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return er.ErrSynonymAlreadyExists
+	}
+	if err == pgx.ErrNoRows {
+		return er.ErrSynonymNotFound
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	"github.com/Gohelraj/youtube-search-api/utils"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// searchQueryBufferSize is the number of search queries waiting to be recorded, beyond which they are dropped
+	searchQueryBufferSize = 256
+	// searchQueryTimeout is the maximum duration of the insert of a search query
+	searchQueryTimeout = 5 * time.Second
+)
+
+// SearchQueryRecorder records the search queries, which suggest the popular queries, in the background so that the
+// searches don't wait for the insert. The queries are dropped while the buffer is full rather than slowing the searches.
+type SearchQueryRecorder struct {
+	videoRepository repository.VideoRepository
+	queries         chan string
+}
+
+// NewSearchQueryRecorder creates a recorder of the search queries, which are inserted once Run is called.
+func NewSearchQueryRecorder(r repository.VideoRepository) *SearchQueryRecorder {
+	return &SearchQueryRecorder{
+		videoRepository: r,
+		queries:         make(chan string, searchQueryBufferSize),
+	}
+}
+
+// Record queues the normalized search string to be recorded, or drops it when the buffer is full
+func (s *SearchQueryRecorder) Record(ctx context.Context, searchString string) {
+	query := strings.Join(utils.SplitWords(searchString), " ")
+	if query == "" || len(query) > 200 {
+		return
+	}
+	select {
+	case s.queries <- query:
+	default:
+		slog.WarnContext(ctx, "Dropping search query, too many queries waiting to be recorded")
+	}
+}
+
+// Run inserts the queued search queries until the context is done, and then inserts the queries still queued, as
+// the searches have finished by the time the workers are stopped.
+func (s *SearchQueryRecorder) Run(ctx context.Context) {
+	for {
+		select {
+		case query := <-s.queries:
+			s.insert(query)
+		case <-ctx.Done():
+			for {
+				select {
+				case query := <-s.queries:
+					s.insert(query)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *SearchQueryRecorder) insert(query string) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchQueryTimeout)
+	defer cancel()
+	if err := s.videoRepository.InsertSearchQuery(ctx, query); err != nil {
+		slog.Error("Error inserting search query", "err", err)
+	}
+}
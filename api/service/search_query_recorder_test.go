@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	"testing"
+)
+
+// fakeSearchQueryRepository records the inserted search queries
+type fakeSearchQueryRepository struct {
+	repository.VideoRepository
+	queries []string
+}
+
+func (f *fakeSearchQueryRepository) InsertSearchQuery(_ context.Context, query string) error {
+	f.queries = append(f.queries, query)
+	return nil
+}
+
+func TestSearchQueryRecorder(t *testing.T) {
+	videoRepository := &fakeSearchQueryRepository{}
+	s := NewSearchQueryRecorder(videoRepository)
+	ctx := context.Background()
+
+	s.Record(ctx, "  IPL   Final! ")
+	s.Record(ctx, "   ")
+	for i := 0; i < searchQueryBufferSize; i++ {
+		s.Record(ctx, "cricket")
+	}
+	if len(s.queries) != searchQueryBufferSize {
+		t.Fatalf("queued %d queries, want %d", len(s.queries), searchQueryBufferSize)
+	}
+
+	// the queued queries are still inserted once the worker is stopped
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	s.Run(canceled)
+	if len(videoRepository.queries) != searchQueryBufferSize {
+		t.Fatalf("inserted %d queries, want %d", len(videoRepository.queries), searchQueryBufferSize)
+	}
+	if videoRepository.queries[0] != "ipl final" || videoRepository.queries[1] != "cricket" {
+		t.Errorf("inserted queries starting with %q, want the normalized queries", videoRepository.queries[:2])
+	}
+}
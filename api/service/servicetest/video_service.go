@@ -0,0 +1,100 @@
+// Package servicetest has the fake services of the tests of the APIs
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/service"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"time"
+)
+
+// ErrInternal is an internal error whose message must never be exposed by the APIs
+var ErrInternal = errors.New(`relation "videos" does not exist`)
+
+// FakeVideoService serves the videos of youtube ids "v0", "v1"... of the channel "c1", and records the requests
+type FakeVideoService struct {
+	service.VideoService
+	Videos []model.VideoMetadata
+	// Err is returned by the methods which serve the videos
+	Err           error
+	VideosRequest model.GetVideosRequest
+	SearchRequest model.SearchVideosRequest
+	Filters       model.VideoFilters
+	// Watched receives the new videos of the watchers
+	Watched chan model.VideoMetadata
+}
+
+// NewFakeVideoService returns the fake service of count videos
+func NewFakeVideoService(count int) *FakeVideoService {
+	channelID, channelTitle, viewCount, duration := "c1", "Channel 1", int64(5_000_000_000), 90
+	s := &FakeVideoService{Watched: make(chan model.VideoMetadata)}
+	for i := 0; i < count; i++ {
+		s.Videos = append(s.Videos, model.VideoMetadata{
+			ID:              int64(i + 1),
+			YoutubeID:       fmt.Sprintf("v%d", i),
+			Title:           fmt.Sprintf("Video %d", i),
+			PublishedAt:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			ChannelID:       &channelID,
+			ChannelTitle:    &channelTitle,
+			ViewCount:       &viewCount,
+			DurationSeconds: &duration,
+		})
+	}
+	return s
+}
+
+func (s *FakeVideoService) page(limit int, offset int) []model.VideoMetadata {
+	if offset > len(s.Videos) {
+		return nil
+	}
+	return s.Videos[offset:min(offset+limit, len(s.Videos))]
+}
+
+func (s *FakeVideoService) GetVideos(_ context.Context, videosRequest model.GetVideosRequest) ([]model.VideoMetadata, error) {
+	s.VideosRequest = videosRequest
+	return s.page(videosRequest.Limit, videosRequest.Offset), s.Err
+}
+
+func (s *FakeVideoService) GetVideo(_ context.Context, youtubeID string) (model.VideoMetadata, error) {
+	for _, video := range s.Videos {
+		if video.YoutubeID == youtubeID {
+			return video, s.Err
+		}
+	}
+	return model.VideoMetadata{}, er.ErrVideoNotFound
+}
+
+// SearchVideos returns the page of the videos and the count of the channel facet
+func (s *FakeVideoService) SearchVideos(_ context.Context, searchRequest model.SearchVideosRequest) (model.SearchVideosResponse, error) {
+	s.SearchRequest = searchRequest
+	label := "Channel 1"
+	return model.SearchVideosResponse{
+		Videos: s.page(searchRequest.Limit, searchRequest.Offset),
+		Facets: map[string][]model.FacetBucket{model.FacetChannel: {{Value: "c1", Label: &label, Count: len(s.Videos)}}},
+	}, s.Err
+}
+
+func (s *FakeVideoService) GetLatestVideos(_ context.Context, _ string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error) {
+	s.Filters = filters
+	return s.page(limit, 0), s.Err
+}
+
+// WatchNewVideos sends the videos received by Watched until the context is done
+func (s *FakeVideoService) WatchNewVideos(ctx context.Context, _ model.WatchVideosRequest) (<-chan model.VideoMetadata, error) {
+	videos := make(chan model.VideoMetadata)
+	go func() {
+		defer close(videos)
+		for {
+			select {
+			case video := <-s.Watched:
+				videos <- video
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return videos, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	"github.com/Gohelraj/youtube-search-api/pkg/importer"
+	"github.com/Gohelraj/youtube-search-api/pkg/youtube"
+	"io"
+)
+
+// importBatchSize is the number of rows inserted at once, which is the maximum number of videos of a videos list API call
+const importBatchSize = 50
+
+// maxImportErrors is the maximum number of the row errors returned in an import result
+const maxImportErrors = 1000
+
+type VideoImportService interface {
+	ImportVideos(ctx context.Context, reader importer.Reader, enrich bool) (model.VideoImportResult, error)
+}
+
+type videoImportService struct {
+	videoRepository       repository.VideoRepository
+	savedSearchRepository repository.SavedSearchRepository
+	webhookRepository     repository.WebhookRepository
+}
+
+// NewVideoImportService creates the video import service, the saved searches and webhooks are notified of the imported
+// videos the same way as of the ingested videos.
+func NewVideoImportService(r repository.VideoRepository, savedSearchRepository repository.SavedSearchRepository, webhookRepository repository.WebhookRepository) VideoImportService {
+	return videoImportService{
+		videoRepository:       r,
+		savedSearchRepository: savedSearchRepository,
+		webhookRepository:     webhookRepository,
+	}
+}
+
+// importRow valid row of an imported file waiting to be inserted
+type importRow struct {
+	line  int
+	video model.VideoMetadata
+}
+
+// ImportVideos inserts the videos read from the reader in batches, skipping the already stored videos. Invalid rows are
+// reported in the result and don't stop the import. When enrich is true, the metadata of the rows having the YouTube id
+// only is fetched from YouTube. An error is returned when the file can't be read or a batch can't be inserted, in which
+// case the earlier batches remain inserted.
+func (v videoImportService) ImportVideos(ctx context.Context, reader importer.Reader, enrich bool) (model.VideoImportResult, error) {
+	result := model.VideoImportResult{Errors: []model.VideoImportError{}}
+	batch := make([]importRow, 0, importBatchSize)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Rows++
+		if row.Err != nil {
+			addImportError(&result, row.Line, row.Video.YoutubeID, row.Err.Error())
+			continue
+		}
+		batch = append(batch, importRow{line: row.Line, video: row.Video})
+		if len(batch) == importBatchSize {
+			if err = v.importBatch(ctx, batch, enrich, &result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := v.importBatch(ctx, batch, enrich, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// importBatch enriches, validates and inserts a batch of rows
+func (v videoImportService) importBatch(ctx context.Context, batch []importRow, enrich bool, result *model.VideoImportResult) error {
+	enrichErrors := map[int]string{}
+	if enrich {
+		enrichErrors = enrichIDOnlyRows(ctx, batch, result)
+	}
+	videos := make([]model.VideoMetadata, 0, len(batch))
+	for i := range batch {
+		if message, ok := enrichErrors[i]; ok {
+			addImportError(result, batch[i].line, batch[i].video.YoutubeID, message)
+			continue
+		}
+		if !enrich && importer.IsIDOnly(batch[i].video) {
+			addImportError(result, batch[i].line, batch[i].video.YoutubeID, "title is required, or enable enrichment to fetch it from YouTube")
+			continue
+		}
+		if err := importer.Normalize(&batch[i].video); err != nil {
+			addImportError(result, batch[i].line, batch[i].video.YoutubeID, err.Error())
+			continue
+		}
+		videos = append(videos, batch[i].video)
+	}
+	if len(videos) == 0 {
+		return nil
+	}
+	insertedVideos, err := youtube.InsertVideos(ctx, v.videoRepository, v.savedSearchRepository, v.webhookRepository, videos)
+	if err != nil {
+		return err
+	}
+	result.Inserted += len(insertedVideos)
+	result.Skipped += len(videos) - len(insertedVideos)
+	return nil
+}
+
+// enrichIDOnlyRows fetches the metadata of the rows of the batch having the YouTube id only, and returns the errors
+// of the rows which couldn't be enriched by their index in the batch
+func enrichIDOnlyRows(ctx context.Context, batch []importRow, result *model.VideoImportResult) map[int]string {
+	enrichErrors := map[int]string{}
+	var indexes []int
+	var videos []model.VideoMetadata
+	for i := range batch {
+		if importer.IsIDOnly(batch[i].video) && batch[i].video.YoutubeID != "" {
+			indexes = append(indexes, i)
+			videos = append(videos, batch[i].video)
+		}
+	}
+	if len(videos) == 0 {
+		return enrichErrors
+	}
+	notFound, err := youtube.EnrichVideos(ctx, videos)
+	if err != nil {
+		for _, i := range indexes {
+			enrichErrors[i] = fmt.Sprintf("fetching the video from YouTube failed: %v", err)
+		}
+		return enrichErrors
+	}
+	isNotFound := make(map[string]bool, len(notFound))
+	for _, youtubeID := range notFound {
+		isNotFound[youtubeID] = true
+	}
+	for j, i := range indexes {
+		if isNotFound[videos[j].YoutubeID] {
+			enrichErrors[i] = "video not found on YouTube"
+			continue
+		}
+		batch[i].video = videos[j]
+		result.Enriched++
+	}
+	return enrichErrors
+}
+
+func addImportError(result *model.VideoImportResult, line int, youtubeID string, message string) {
+	result.Failed++
+	if len(result.Errors) < maxImportErrors {
+		result.Errors = append(result.Errors, model.VideoImportError{Line: line, YoutubeID: youtubeID, Error: message})
+	}
+}
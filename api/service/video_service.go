@@ -1,29 +1,373 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"github.com/Gohelraj/youtube-search-api/api/model"
 	"github.com/Gohelraj/youtube-search-api/api/repository"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/Gohelraj/youtube-search-api/pkg/lru"
+	"github.com/Gohelraj/youtube-search-api/pkg/notify"
+	"github.com/Gohelraj/youtube-search-api/utils"
+	"github.com/jackc/pgx/v4"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 )
 
 type VideoService interface {
-	GetVideos(limit int, offset int) ([]model.VideoMetadata, error)
-	SearchVideos(searchString string) ([]model.VideoMetadata, error)
+	GetVideos(ctx context.Context, videosRequest model.GetVideosRequest) ([]model.VideoMetadata, error)
+	GetVideo(ctx context.Context, youtubeID string) (model.VideoMetadata, error)
+	GetSimilarVideos(ctx context.Context, youtubeID string, limit int, publishedAfter time.Time, publishedBefore time.Time) ([]model.VideoMetadata, error)
+	SearchVideos(ctx context.Context, searchRequest model.SearchVideosRequest) (model.SearchVideosResponse, error)
+	SuggestVideos(ctx context.Context, searchString string, limit int) (model.VideoSuggestions, error)
+	WatchNewVideos(ctx context.Context, watchRequest model.WatchVideosRequest) (<-chan model.VideoMetadata, error)
+	GetLatestVideos(ctx context.Context, query string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error)
+	ExportVideos(ctx context.Context, videosRequest model.GetVideosRequest, each func(video model.VideoMetadata) error) error
 }
 
 type videoService struct {
 	videoRepository repository.VideoRepository
+	suggestionCache *lru.Cache[model.VideoSuggestions]
+	videosInserted  *notify.Listener
+	searchQueries   *SearchQueryRecorder
 }
 
-func NewVideoService(r repository.VideoRepository) VideoService {
+// NewVideoService creates the video service, whose new videos are watched by subscribing to the given listener
+// of the videos inserted channel, and whose search queries are recorded by the given recorder.
+func NewVideoService(r repository.VideoRepository, videosInserted *notify.Listener, searchQueries *SearchQueryRecorder) VideoService {
 	return videoService{
 		videoRepository: r,
+		suggestionCache: lru.New[model.VideoSuggestions](config.Conf.Search.SuggestCacheSize, config.Conf.Search.SuggestCacheTTL),
+		videosInserted:  videosInserted,
+		searchQueries:   searchQueries,
 	}
 }
 
-func (v videoService) GetVideos(limit int, offset int) ([]model.VideoMetadata, error) {
-	return v.videoRepository.GetVideos(limit, offset)
+const (
+	// DefaultPageSize is the number of videos of a page whose limit isn't given
+	DefaultPageSize = 50
+	// MaxPageSize is the maximum number of videos of a page
+	MaxPageSize = 100
+)
+
+// ValidatePage validates the limit and offset of a page of videos requested by GetVideos or SearchVideos, so that
+// every API has the same bounds
+func ValidatePage(limit int, offset int) error {
+	if limit < 1 {
+		return er.ErrInvalidValueInLimit
+	}
+	if limit > MaxPageSize {
+		return er.ErrLimitExceeded
+	}
+	if offset < 0 {
+		return er.ErrInvalidValueInOffset
+	}
+	return nil
+}
+
+func (v videoService) GetVideos(ctx context.Context, videosRequest model.GetVideosRequest) ([]model.VideoMetadata, error) {
+	return v.videoRepository.GetVideos(ctx, videosRequest)
+}
+
+// GetVideo returns the video of the given YouTube id, er.ErrVideoNotFound is returned if it doesn't exist
+func (v videoService) GetVideo(ctx context.Context, youtubeID string) (model.VideoMetadata, error) {
+	video, err := v.videoRepository.GetVideo(ctx, youtubeID)
+	if err == pgx.ErrNoRows {
+		return video, er.ErrVideoNotFound
+	}
+	return video, err
+}
+
+// ExportVideos calls each for the videos of GetVideos one by one, a zero limit exports all the videos after the offset
+func (v videoService) ExportVideos(ctx context.Context, videosRequest model.GetVideosRequest, each func(video model.VideoMetadata) error) error {
+	return v.videoRepository.ExportVideos(ctx, videosRequest, each)
+}
+
+// GetLatestVideos returns the latest published videos matching the optional query and filters
+func (v videoService) GetLatestVideos(ctx context.Context, query string, filters model.VideoFilters, limit int) ([]model.VideoMetadata, error) {
+	query = strings.TrimSpace(query)
+	if len(query) > 200 {
+		return nil, er.ErrInvalidQuery
+	}
+	if err := normalizeVideoFilters(&filters); err != nil {
+		return nil, err
+	}
+	return v.videoRepository.GetLatestVideos(ctx, query, filters, limit)
+}
+
+// GetSimilarVideos returns the videos related to the video of the given YouTube id
+func (v videoService) GetSimilarVideos(ctx context.Context, youtubeID string, limit int, publishedAfter time.Time, publishedBefore time.Time) ([]model.VideoMetadata, error) {
+	if _, err := v.videoRepository.GetVideo(ctx, youtubeID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, er.ErrVideoNotFound
+		}
+		return nil, err
+	}
+	return v.videoRepository.GetSimilarVideos(ctx, youtubeID, limit, publishedAfter, publishedBefore)
+}
+
+// SearchVideos searches videos matching the search string. In fuzzy mode, when the full text search returns
+// too few hits, videos with similar titles are blended in and a spelling suggestion is returned.
+func (v videoService) SearchVideos(ctx context.Context, searchRequest model.SearchVideosRequest) (model.SearchVideosResponse, error) {
+	response := model.SearchVideosResponse{}
+	if err := validateSearchOptions(&searchRequest); err != nil {
+		return response, err
+	}
+	ranking, err := resolveRankingOptions(searchRequest.Ranking)
+	if err != nil {
+		return response, err
+	}
+	searchRequest.Ranking = &ranking
+	fuzzy := false
+	if searchRequest.Fuzzy {
+		hits, err := v.videoRepository.CountSearchVideos(ctx, searchRequest)
+		if err != nil {
+			return response, err
+		}
+		if hits < config.Conf.Search.FuzzyMinHits {
+			fuzzy = true
+			words := utils.SplitWords(searchRequest.SearchString)
+			suggestion, err := v.videoRepository.GetSpellingSuggestion(ctx, words)
+			if err != nil {
+				return response, err
+			}
+			if suggestion != strings.Join(words, " ") {
+				response.DidYouMean = &suggestion
+			}
+		}
+	}
+	videos, err := v.videoRepository.SearchVideos(ctx, searchRequest, fuzzy)
+	if err != nil {
+		return response, err
+	}
+	response.Videos = videos
+	if len(searchRequest.Facets) > 0 {
+		response.Facets, err = v.videoRepository.GetSearchFacets(ctx, searchRequest, fuzzy)
+		if err != nil {
+			return response, err
+		}
+	}
+	// record only the first page of a search, so that paginating doesn't make a query popular
+	if searchRequest.Offset == 0 {
+		v.searchQueries.Record(ctx, searchRequest.SearchString)
+	}
+	return response, nil
+}
+
+// validateSearchOptions validates the language, facets and highlight options of the search request and sets the
+// default highlight selectors
+func validateSearchOptions(searchRequest *model.SearchVideosRequest) error {
+	if searchRequest.Language != "" {
+		if _, ok := utils.TextSearchConfig(searchRequest.Language); !ok {
+			return er.ErrUnsupportedLanguage
+		}
+	}
+	for _, facet := range searchRequest.Facets {
+		if utils.GetIndexOf(facet, model.SearchFacets) == -1 {
+			return er.ErrInvalidFacet
+		}
+	}
+	if highlight := searchRequest.Highlight; highlight != nil {
+		if highlight.StartSel == "" && highlight.StopSel == "" {
+			highlight.StartSel, highlight.StopSel = "<b>", "</b>"
+		}
+		// double quotes are used to quote the selectors in ts_headline options
+		if len(highlight.StartSel) > 20 || len(highlight.StopSel) > 20 ||
+			strings.Contains(highlight.StartSel, `"`) || strings.Contains(highlight.StopSel, `"`) {
+			return er.ErrInvalidHighlightSelector
+		}
+		if highlight.MaxFragments < 0 || highlight.MaxFragments > 10 {
+			return er.ErrInvalidHighlightFragments
+		}
+	}
+	return nil
+}
+
+// defaultRankingOptions ranks search results by the text rank only, weighting the title higher than the description
+func defaultRankingOptions() model.RankingOptions {
+	normalization, titleWeight, descriptionWeight, recencyBoost, popularityBoost := 0, 1.0, 0.4, 0.0, 0.0
+	return model.RankingOptions{RankingProfile: config.RankingProfile{
+		Function:          model.RankFunctionTsRank,
+		Normalization:     &normalization,
+		TitleWeight:       &titleWeight,
+		DescriptionWeight: &descriptionWeight,
+		RecencyBoost:      &recencyBoost,
+		PopularityBoost:   &popularityBoost,
+	}}
 }
 
-func (v videoService) SearchVideos(searchString string) ([]model.VideoMetadata, error) {
-	return v.videoRepository.SearchVideos(searchString)
+// resolveRankingOptions returns the ranking options of a search, the options which are not set in the given options are
+// taken from the requested ranking profile, which defaults to the "default" profile, and then from defaultRankingOptions.
+func resolveRankingOptions(options *model.RankingOptions) (model.RankingOptions, error) {
+	ranking := defaultRankingOptions()
+	profileName := "default"
+	if options != nil && options.Profile != "" {
+		profileName = options.Profile
+	}
+	profile, ok := config.Conf.Search.RankingProfiles[profileName]
+	if !ok && profileName != "default" {
+		return ranking, er.ErrUnknownRankingProfile
+	}
+	overrideRankingOptions(&ranking, profile)
+	if options != nil {
+		overrideRankingOptions(&ranking, options.RankingProfile)
+	}
+	ranking.Profile = profileName
+	if ranking.Function != model.RankFunctionTsRank && ranking.Function != model.RankFunctionTsRankCd {
+		return ranking, er.ErrInvalidRankFunction
+	}
+	// normalization is a bit mask of the 6 normalization methods of ts_rank
+	if *ranking.Normalization < 0 || *ranking.Normalization > 63 {
+		return ranking, er.ErrInvalidRankNormalization
+	}
+	if *ranking.TitleWeight < 0 || *ranking.TitleWeight > 1 || *ranking.DescriptionWeight < 0 || *ranking.DescriptionWeight > 1 {
+		return ranking, er.ErrInvalidRankWeight
+	}
+	if (ranking.RecencyHalfLifeHours != nil && *ranking.RecencyHalfLifeHours <= 0) || *ranking.RecencyBoost < 0 || *ranking.PopularityBoost < 0 {
+		return ranking, er.ErrInvalidRankBoost
+	}
+	return ranking, nil
+}
+
+// overrideRankingOptions overrides the ranking options with the options which are set in overrides
+func overrideRankingOptions(ranking *model.RankingOptions, overrides config.RankingProfile) {
+	if overrides.Function != "" {
+		ranking.Function = overrides.Function
+	}
+	if overrides.Normalization != nil {
+		ranking.Normalization = overrides.Normalization
+	}
+	if overrides.TitleWeight != nil {
+		ranking.TitleWeight = overrides.TitleWeight
+	}
+	if overrides.DescriptionWeight != nil {
+		ranking.DescriptionWeight = overrides.DescriptionWeight
+	}
+	if overrides.RecencyHalfLifeHours != nil {
+		ranking.RecencyHalfLifeHours = overrides.RecencyHalfLifeHours
+	}
+	if overrides.RecencyBoost != nil {
+		ranking.RecencyBoost = overrides.RecencyBoost
+	}
+	if overrides.PopularityBoost != nil {
+		ranking.PopularityBoost = overrides.PopularityBoost
+	}
+}
+
+// SuggestVideos returns title and popular query completions of the partially typed search string.
+// The suggestions are served from an in-memory cache of hot prefixes, and when the queries exceed
+// the latency budget, whatever completed in time is returned.
+func (v videoService) SuggestVideos(ctx context.Context, searchString string, limit int) (model.VideoSuggestions, error) {
+	suggestions := model.VideoSuggestions{Titles: []string{}, Queries: []string{}}
+	words := utils.SplitWords(searchString)
+	if len(words) == 0 {
+		return suggestions, nil
+	}
+	prefix := strings.Join(words, " ")
+	cacheKey := fmt.Sprintf("%d:%s", limit, prefix)
+	if cached, ok := v.suggestionCache.Get(cacheKey); ok {
+		return cached, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Conf.Search.SuggestTimeout)
+	defer cancel()
+	var titlesErr, queriesErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// the last word is being typed, so only it is matched as a prefix
+		titles, err := v.videoRepository.SuggestTitles(ctx, strings.Join(words[:len(words)-1], " "), words[len(words)-1], limit)
+		suggestions.Titles, titlesErr = titles, err
+	}()
+	go func() {
+		defer wg.Done()
+		queries, err := v.videoRepository.SuggestQueries(ctx, prefix, limit)
+		suggestions.Queries, queriesErr = queries, err
+	}()
+	wg.Wait()
+
+	timedOut := false
+	for _, err := range []error{titlesErr, queriesErr} {
+		if err == nil {
+			continue
+		}
+		if ctx.Err() == nil {
+			return model.VideoSuggestions{}, err
+		}
+		timedOut = true
+	}
+	if suggestions.Titles == nil {
+		suggestions.Titles = []string{}
+	}
+	if suggestions.Queries == nil {
+		suggestions.Queries = []string{}
+	}
+	// partial suggestions are not cached, so that the next request can complete them
+	if !timedOut {
+		v.suggestionCache.Add(cacheKey, suggestions)
+	}
+	return suggestions, nil
+}
+
+// watchBatchSize is the number of new videos queried at once while watching them
+const watchBatchSize = 100
+
+// WatchNewVideos returns a channel of the videos matching the query and filters of the watch request, which are
+// inserted after the video of its after id, or from now on if it is not set. The channel is closed once the context
+// is done, or when querying the new videos fails, after which the watch can be resumed from the last received video.
+func (v videoService) WatchNewVideos(ctx context.Context, watchRequest model.WatchVideosRequest) (<-chan model.VideoMetadata, error) {
+	watchRequest.Query = strings.TrimSpace(watchRequest.Query)
+	if len(watchRequest.Query) > 200 {
+		return nil, er.ErrInvalidQuery
+	}
+	if err := normalizeVideoFilters(&watchRequest.Filters); err != nil {
+		return nil, err
+	}
+	// subscribing before looking up the last video makes sure no video inserted meanwhile is missed
+	wake, unsubscribe := v.videosInserted.Subscribe()
+	afterID := int64(0)
+	if watchRequest.AfterID != nil {
+		afterID = *watchRequest.AfterID
+	} else {
+		lastID, err := v.videoRepository.GetLastVideoID(ctx)
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+		afterID = lastID
+	}
+	videos := make(chan model.VideoMetadata)
+	go func() {
+		defer close(videos)
+		defer unsubscribe()
+		for {
+			newVideos, err := v.videoRepository.GetVideosAfter(ctx, afterID, watchRequest.Query, watchRequest.Filters, watchBatchSize)
+			if err != nil {
+				slog.ErrorContext(ctx, "Error getting new videos", "after_id", afterID, "err", err)
+				return
+			}
+			for _, video := range newVideos {
+				select {
+				case videos <- video:
+					afterID = video.ID
+				case <-ctx.Done():
+					return
+				}
+			}
+			// the next batch is queried right away until the backlog is caught up, and then after the next notification
+			if len(newVideos) == watchBatchSize {
+				continue
+			}
+			select {
+			case <-wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return videos, nil
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/config"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"testing"
+)
+
+func TestValidatePage(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		offset int
+		want   error
+	}{
+		{"valid", 10, 20, nil},
+		{"max page size", MaxPageSize, 0, nil},
+		{"zero limit", 0, 0, er.ErrInvalidValueInLimit},
+		{"negative limit", -1, 0, er.ErrInvalidValueInLimit},
+		{"limit exceeded", MaxPageSize + 1, 0, er.ErrLimitExceeded},
+		{"negative offset", 10, -1, er.ErrInvalidValueInOffset},
+	}
+	for _, test := range tests {
+		if err := ValidatePage(test.limit, test.offset); err != test.want {
+			t.Errorf("%s: ValidatePage(%d, %d) = %v, want %v", test.name, test.limit, test.offset, err, test.want)
+		}
+	}
+}
+
+func TestValidateSearchOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		searchRequest model.SearchVideosRequest
+		want          error
+	}{
+		{"valid", model.SearchVideosRequest{Language: "en", Facets: []string{model.FacetChannel}}, nil},
+		{"unsupported language", model.SearchVideosRequest{Language: "klingon"}, er.ErrUnsupportedLanguage},
+		{"invalid facet", model.SearchVideosRequest{Facets: []string{"title"}}, er.ErrInvalidFacet},
+		{"quoted selector", model.SearchVideosRequest{Highlight: &model.HighlightOptions{StartSel: `"`, StopSel: "</b>"}}, er.ErrInvalidHighlightSelector},
+		{"too many fragments", model.SearchVideosRequest{Highlight: &model.HighlightOptions{MaxFragments: 11}}, er.ErrInvalidHighlightFragments},
+	}
+	for _, test := range tests {
+		if err := validateSearchOptions(&test.searchRequest); err != test.want {
+			t.Errorf("%s: validateSearchOptions() = %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	searchRequest := model.SearchVideosRequest{Highlight: &model.HighlightOptions{}}
+	if err := validateSearchOptions(&searchRequest); err != nil || searchRequest.Highlight.StartSel != "<b>" || searchRequest.Highlight.StopSel != "</b>" {
+		t.Errorf("highlight = %+v, %v, want the default selectors", searchRequest.Highlight, err)
+	}
+}
+
+func TestResolveRankingOptions(t *testing.T) {
+	recencyBoost, halfLife, fresherHalfLife := 0.5, 24.0, 6.0
+	config.Conf.Search.RankingProfiles = map[string]config.RankingProfile{
+		"fresh": {Function: model.RankFunctionTsRankCd, RecencyBoost: &recencyBoost, RecencyHalfLifeHours: &halfLife},
+	}
+	defer func() { config.Conf.Search.RankingProfiles = nil }()
+	zero, negative, outOfRange, overWeight := 0.0, -1.0, 64, 1.5
+
+	tests := []struct {
+		name    string
+		options *model.RankingOptions
+		want    error
+		check   func(ranking model.RankingOptions) bool
+	}{
+		{"default", nil, nil, func(ranking model.RankingOptions) bool {
+			return ranking.Profile == "default" && ranking.Function == model.RankFunctionTsRank && *ranking.TitleWeight == 1 && ranking.RecencyHalfLifeHours == nil
+		}},
+		{"profile", &model.RankingOptions{Profile: "fresh"}, nil, func(ranking model.RankingOptions) bool {
+			return ranking.Profile == "fresh" && ranking.Function == model.RankFunctionTsRankCd && *ranking.RecencyBoost == 0.5 &&
+				*ranking.RecencyHalfLifeHours == 24 && *ranking.DescriptionWeight == 0.4
+		}},
+		{"request overriding the profile", &model.RankingOptions{Profile: "fresh", RankingProfile: config.RankingProfile{RecencyHalfLifeHours: &fresherHalfLife}}, nil,
+			func(ranking model.RankingOptions) bool {
+				return ranking.Function == model.RankFunctionTsRankCd && *ranking.RecencyBoost == 0.5 && *ranking.RecencyHalfLifeHours == 6
+			}},
+		{"unknown profile", &model.RankingOptions{Profile: "stale"}, er.ErrUnknownRankingProfile, nil},
+		{"unknown function", &model.RankingOptions{RankingProfile: config.RankingProfile{Function: "bm25"}}, er.ErrInvalidRankFunction, nil},
+		{"normalization out of range", &model.RankingOptions{RankingProfile: config.RankingProfile{Normalization: &outOfRange}}, er.ErrInvalidRankNormalization, nil},
+		{"weight out of range", &model.RankingOptions{RankingProfile: config.RankingProfile{TitleWeight: &overWeight}}, er.ErrInvalidRankWeight, nil},
+		{"zero half-life", &model.RankingOptions{RankingProfile: config.RankingProfile{RecencyHalfLifeHours: &zero}}, er.ErrInvalidRankBoost, nil},
+		{"negative half-life overriding the profile", &model.RankingOptions{Profile: "fresh", RankingProfile: config.RankingProfile{RecencyHalfLifeHours: &negative}}, er.ErrInvalidRankBoost, nil},
+		{"negative boost", &model.RankingOptions{RankingProfile: config.RankingProfile{PopularityBoost: &negative}}, er.ErrInvalidRankBoost, nil},
+	}
+	for _, test := range tests {
+		ranking, err := resolveRankingOptions(test.options)
+		if err != test.want {
+			t.Errorf("%s: resolveRankingOptions() error = %v, want %v", test.name, err, test.want)
+			continue
+		}
+		if test.check != nil && !test.check(ranking) {
+			t.Errorf("%s: ranking = %+v", test.name, ranking)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	er "github.com/Gohelraj/youtube-search-api/error"
+	"github.com/jackc/pgx/v4"
+	"net/url"
+	"strings"
+)
+
+type WebhookService interface {
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (model.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	GetDeliveries(ctx context.Context, webhookID int64, limit int, offset int) ([]model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, webhookID int64, id int64) (model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID int64, id int64) (model.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhookRepository repository.WebhookRepository
+}
+
+func NewWebhookService(r repository.WebhookRepository) WebhookService {
+	return webhookService{
+		webhookRepository: r,
+	}
+}
+
+func (s webhookService) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	webhooks, err := s.webhookRepository.GetWebhooks(ctx)
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, err
+}
+
+func (s webhookService) GetWebhook(ctx context.Context, id int64) (model.Webhook, error) {
+	webhook, err := s.webhookRepository.GetWebhook(ctx, id)
+	if err == pgx.ErrNoRows {
+		return webhook, er.ErrWebhookNotFound
+	}
+	webhook.Secret = ""
+	return webhook, err
+}
+
+func (s webhookService) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	if err := normalizeWebhook(&webhook); err != nil {
+		return webhook, err
+	}
+	webhook, err := s.webhookRepository.InsertWebhook(ctx, webhook)
+	webhook.Secret = ""
+	return webhook, err
+}
+
+func (s webhookService) UpdateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	if err := normalizeWebhook(&webhook); err != nil {
+		return webhook, err
+	}
+	webhook, err := s.webhookRepository.UpdateWebhook(ctx, webhook)
+	if err == pgx.ErrNoRows {
+		return webhook, er.ErrWebhookNotFound
+	}
+	webhook.Secret = ""
+	return webhook, err
+}
+
+func (s webhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	deleted, err := s.webhookRepository.DeleteWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return er.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s webhookService) GetDeliveries(ctx context.Context, webhookID int64, limit int, offset int) ([]model.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepository.GetWebhookDeliveries(ctx, webhookID, limit, offset)
+}
+
+func (s webhookService) GetDelivery(ctx context.Context, webhookID int64, id int64) (model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepository.GetWebhookDelivery(ctx, webhookID, id)
+	if err == pgx.ErrNoRows {
+		return delivery, er.ErrWebhookDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// Redeliver makes the delivery pending again, it is attempted by the delivery worker with a fresh budget of attempts
+func (s webhookService) Redeliver(ctx context.Context, webhookID int64, id int64) (model.WebhookDelivery, error) {
+	found, err := s.webhookRepository.RedeliverWebhookDelivery(ctx, webhookID, id)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if !found {
+		return model.WebhookDelivery{}, er.ErrWebhookDeliveryNotFound
+	}
+	return s.GetDelivery(ctx, webhookID, id)
+}
+
+// normalizeWebhook trims the fields of the webhook and validates them
+func normalizeWebhook(webhook *model.Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	webhookURL, err := url.Parse(webhook.URL)
+	if err != nil || webhookURL.Scheme != "http" && webhookURL.Scheme != "https" || webhookURL.Host == "" || len(webhook.URL) > 2000 {
+		return er.ErrInvalidWebhookURL
+	}
+	// the secret is used as is, as it is shared with the receiver to verify the signatures
+	if len(webhook.Secret) < 16 || len(webhook.Secret) > 200 {
+		return er.ErrInvalidWebhookSecret
+	}
+	webhook.Query = trimToNil(webhook.Query)
+	if webhook.Query != nil && len(*webhook.Query) > 200 {
+		return er.ErrInvalidWebhookQuery
+	}
+	return normalizeVideoFilters(&webhook.Filters)
+}
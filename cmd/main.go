@@ -1,45 +1,340 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/grpc"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
 	"github.com/Gohelraj/youtube-search-api/api/route"
+	"github.com/Gohelraj/youtube-search-api/api/service"
 	"github.com/Gohelraj/youtube-search-api/config"
 	"github.com/Gohelraj/youtube-search-api/db"
 	"github.com/Gohelraj/youtube-search-api/pkg/cron_job"
+	"github.com/Gohelraj/youtube-search-api/pkg/export"
+	"github.com/Gohelraj/youtube-search-api/pkg/importer"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"github.com/Gohelraj/youtube-search-api/pkg/notify"
+	"github.com/Gohelraj/youtube-search-api/pkg/ratelimit"
+	"github.com/Gohelraj/youtube-search-api/pkg/tracing"
+	"github.com/Gohelraj/youtube-search-api/pkg/webhook"
 	"github.com/Gohelraj/youtube-search-api/pkg/youtube"
-	"log"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 func main() {
 	err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("error loading config: %v\n", err)
+		fatal("error loading config", err)
 	}
+	if err = logging.Init(); err != nil {
+		fatal("error initializing logging", err)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		fatal("error initializing tracing", err)
+	}
+	// flushes the pending spans when the command or the server exits
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("error flushing spans", "err", err)
+		}
+	}()
 
 	pgxPool, err := db.Connect()
 	if err != nil {
-		log.Fatalf("error connecting to db: %v\n", err)
+		fatal("error connecting to db", err)
 	}
 	// closes db connection after the server is shut down
 	defer pgxPool.Close()
 
+	// run the given command instead of the server, e.g. "youtube-search-api reindex"
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:], pgxPool); err != nil {
+			fatal("error running command", err, "command", os.Args[1])
+		}
+		return
+	}
+
+	// the servers are shut down gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// the background workers run until the servers are shut down, and are stopped before the connection pool is closed
+	workersCtx, cancelWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	startWorker := func(worker func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker(workersCtx)
+		}()
+	}
+
+	// a single connection listens to the inserted videos for all the watchers of new videos
+	videosInserted := notify.NewListener(pgxPool, repository.VideosInsertedChannel)
+	startWorker(videosInserted.Listen)
+	videoRepository := repository.NewVideoRepo(pgxPool)
+	searchQueries := service.NewSearchQueryRecorder(videoRepository)
+	startWorker(searchQueries.Run)
+	// the REST, GraphQL and gRPC APIs share the same services
+	videoService := service.NewVideoService(videoRepository, videosInserted, searchQueries)
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepo(pgxPool))
+	limiter := newRateLimiter(pgxPool)
+
 	port := fmt.Sprintf(":%d", config.Conf.Port)
+	// stopping is closed once the server is shutting down, so that the SSE and NDJSON streams end while the other
+	// requests finish
+	stopping := make(chan struct{})
 	// Start the server
 	srv := &http.Server{
 		Addr:    port,
-		Handler: route.InitializeRouter(pgxPool),
+		Handler: route.InitializeRouter(pgxPool, videoService, apiClientService, limiter, stopping),
 		// IdleTimeout is the maximum amount of time to wait for the
 		// next request when keep-alives are enabled.
 		IdleTimeout: 2 * time.Minute,
 	}
+	srv.RegisterOnShutdown(func() {
+		close(stopping)
+	})
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", config.Conf.GRPCPort))
+	if err != nil {
+		fatal("error listening to gRPC port", err, "port", config.Conf.GRPCPort)
+	}
+	grpcServer := grpc.NewServer(videoService, apiClientService, limiter)
 
 	// Start amqp consumer to process youtube videos from queue
-	go youtube.ProcessYoutubeVideosFromQueue(pgxPool)
-	// start event scheduler on app start
-	go cron_job.Init(pgxPool)
+	startWorker(func(ctx context.Context) {
+		youtube.ProcessYoutubeVideosFromQueue(ctx, pgxPool)
+	})
+	// Start delivering the newly inserted videos to the webhooks
+	startWorker(func(ctx context.Context) {
+		webhook.StartDeliveryWorker(ctx, pgxPool)
+	})
+	// start event scheduler on app start, it only registers the jobs and runs them in the background
+	cron_job.Init(pgxPool)
+
+	serverErrors := make(chan error, 2)
+	go func() {
+		slog.Info("Server listening", "port", config.Conf.Port)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+	go func() {
+		slog.Info("gRPC server listening", "port", config.Conf.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			serverErrors <- err
+		}
+	}()
+
+	var serverErr error
+	select {
+	case serverErr = <-serverErrors:
+	case <-ctx.Done():
+		slog.Info("Shutting down servers", "timeout", config.Conf.ShutdownTimeout.String())
+	}
+	shutdown(srv, grpcServer)
+	cancelWorkers()
+	stopWorkers(&workers)
+	if serverErr != nil {
+		fatal("server stopped", serverErr)
+	}
+}
+
+// shutdown stops accepting requests and waits for the pending ones to finish, the servers are closed right away
+// once the shutdown timeout is over
+func shutdown(srv *http.Server, grpcServer *grpc.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Conf.ShutdownTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Warn("Closing server after shutdown timeout", "err", err)
+			_ = srv.Close()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Shutdown(ctx); err != nil {
+			slog.Warn("Stopped gRPC server after shutdown timeout", "err", err)
+		}
+	}()
+	wg.Wait()
+}
+
+// newRateLimiter creates the rate limiter of the configured store
+func newRateLimiter(pgxPool *pgxpool.Pool) ratelimit.Limiter {
+	if config.Conf.RateLimit.Store == "postgres" {
+		return ratelimit.NewPostgresLimiter(pgxPool)
+	}
+	return ratelimit.NewMemoryLimiter()
+}
+
+// stopWorkers waits for the canceled background workers and the running cron jobs to finish, at most for the shutdown
+// timeout
+func stopWorkers(workers *sync.WaitGroup) {
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		<-cron_job.Stop().Done()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(config.Conf.ShutdownTimeout):
+		slog.Warn("Stopped waiting for the background workers after shutdown timeout")
+	}
+}
 
-	log.Printf("Server listening on port: %s", port)
-	log.Fatal(srv.ListenAndServe())
+// fatal logs the error and exits, as slog has no fatal level
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append([]any{"err", err}, args...)...)
+	os.Exit(1)
+}
+
+// runCommand runs the command of the given name with its arguments
+func runCommand(name string, args []string, pgxPool *pgxpool.Pool) error {
+	switch name {
+	case "reindex":
+		// rebuilds the search index of all the videos, required after changing the search stopwords
+		reindexed, err := repository.NewVideoRepo(pgxPool).ReindexVideos(context.Background(), 1000)
+		if err != nil {
+			return err
+		}
+		slog.Info("Reindexed videos", "videos", reindexed)
+		return nil
+	case "export":
+		return exportVideos(args, pgxPool)
+	case "import":
+		return importVideos(args, pgxPool)
+	case "create-api-client":
+		return createAPIClient(args, pgxPool)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// exportVideos writes the videos to a file or stdout, e.g. "youtube-search-api export -format parquet -output videos.parquet"
+func exportVideos(args []string, pgxPool *pgxpool.Pool) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", export.FormatNDJSON, "format of the exported videos: ndjson, csv or parquet")
+	output := flags.String("output", "", "file the videos are written to, stdout if it is not given")
+	limit := flags.Int("limit", 0, "maximum number of exported videos, all the videos if it is 0")
+	offset := flags.Int("offset", 0, "number of skipped latest videos")
+	collapse := flags.Bool("collapse", false, "export only the first published video of each cluster of near-duplicates")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 0 || *offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative")
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	writer, err := export.NewWriter(*format, out)
+	if err != nil {
+		return err
+	}
+	exported := 0
+	videosRequest := model.GetVideosRequest{Limit: *limit, Offset: *offset, Collapse: *collapse}
+	err = repository.NewVideoRepo(pgxPool).ExportVideos(context.Background(), videosRequest, func(video model.VideoMetadata) error {
+		exported++
+		return writer.Write(video)
+	})
+	if err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	if *output != "" {
+		// the file is closed explicitly too, as its write errors are only reported on close
+		if err = out.Close(); err != nil {
+			return err
+		}
+	}
+	slog.Info("Exported videos", "videos", exported)
+	return nil
+}
+
+// importVideos inserts the videos of an NDJSON or CSV file, e.g. "youtube-search-api import -enrich videos.csv"
+func importVideos(args []string, pgxPool *pgxpool.Pool) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "format of the file: ndjson or csv, from the file extension if it is not given")
+	enrich := flags.Bool("enrich", false, "fetch the metadata of the videos having the YouTube id only from YouTube")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("the imported file is required, or - for stdin")
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	in := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	reader, err := importer.NewReader(*format, in)
+	if err != nil {
+		return err
+	}
+	importService := service.NewVideoImportService(repository.NewVideoRepo(pgxPool), repository.NewSavedSearchRepo(pgxPool), repository.NewWebhookRepo(pgxPool))
+	result, err := importService.ImportVideos(context.Background(), reader, *enrich)
+	for _, rowError := range result.Errors {
+		slog.Warn("Invalid row", "line", rowError.Line, "youtube_id", rowError.YoutubeID, "err", rowError.Error)
+	}
+	slog.Info("Imported videos", "rows", result.Rows, "inserted", result.Inserted, "already_stored", result.Skipped,
+		"failed", result.Failed, "enriched", result.Enriched)
+	return err
+}
+
+// createAPIClient issues a key to a new API client, e.g. the first admin key with
+// "youtube-search-api create-api-client -name ops -scopes admin"
+func createAPIClient(args []string, pgxPool *pgxpool.Pool) error {
+	flags := flag.NewFlagSet("create-api-client", flag.ContinueOnError)
+	name := flags.String("name", "", "name of the client")
+	scopes := flags.String("scopes", model.ScopeRead, "comma separated scopes of the client: read, search and admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	apiClientService := service.NewAPIClientService(repository.NewAPIClientRepo(pgxPool))
+	client, err := apiClientService.CreateAPIClient(context.Background(), model.APIClient{Name: *name, Scopes: strings.Split(*scopes, ",")})
+	if err != nil {
+		return err
+	}
+	slog.Info("Created API client", "id", client.ID, "name", client.Name, "scopes", strings.Join(client.Scopes, ","))
+	// the key is printed to stdout alone so that it can be piped, it can't be shown again
+	fmt.Println(client.Key)
+	return nil
 }
@@ -1,21 +1,35 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/spf13/viper"
-	"log"
+	"log/slog"
+	"net"
 	"strings"
+	"time"
 )
 
 var Conf Config
 
 type Config struct {
-	Port                   uint     `mapstructure:"PORT"`
-	Database               Database `mapstructure:",squash"`
-	CronSpecsToFetchVideos string   `mapstructure:"CRON_TO_FETCH_VIDEOS"`
-	VideoKeyword           string   `mapstructure:"KEYWORD_TO_FETCH_VIDEOS"`
+	Port                   uint          `mapstructure:"PORT"`
+	GRPCPort               uint          `mapstructure:"GRPC_PORT"`
+	ShutdownTimeout        time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	Database               Database      `mapstructure:",squash"`
+	CronSpecsToFetchVideos string        `mapstructure:"CRON_TO_FETCH_VIDEOS"`
+	VideoKeyword           string        `mapstructure:"KEYWORD_TO_FETCH_VIDEOS"`
 	GoogleAPIKeys          []string
 	ActiveGoogleAPIKey     string
-	Ampq                   Amqp `mapstructure:",squash"`
+	Ampq                   Amqp      `mapstructure:",squash"`
+	Search                 Search    `mapstructure:",squash"`
+	Webhook                Webhook   `mapstructure:",squash"`
+	Auth                   Auth      `mapstructure:",squash"`
+	RateLimit              RateLimit `mapstructure:",squash"`
+	Tracing                Tracing   `mapstructure:",squash"`
+	Log                    Log       `mapstructure:",squash"`
+	Health                 Health    `mapstructure:",squash"`
+	GraphQL                GraphQL   `mapstructure:",squash"`
 }
 
 type Amqp struct {
@@ -23,6 +37,96 @@ type Amqp struct {
 	QueueName string `mapstructure:"AMQP_QUEUE_NAME"`
 }
 
+type Search struct {
+	// FuzzyMinHits is the number of full text search hits below which fuzzy search blends in trigram similarity
+	FuzzyMinHits int `mapstructure:"FUZZY_SEARCH_MIN_HITS"`
+	// SuggestTimeout is the latency budget of the autocomplete queries
+	SuggestTimeout time.Duration `mapstructure:"SEARCH_SUGGEST_TIMEOUT"`
+	// SuggestCacheSize is the number of hot prefixes whose suggestions are cached in memory
+	SuggestCacheSize int           `mapstructure:"SEARCH_SUGGEST_CACHE_SIZE"`
+	SuggestCacheTTL  time.Duration `mapstructure:"SEARCH_SUGGEST_CACHE_TTL"`
+	// RankingProfiles are the named scoring models of search results, the "default" profile is used when none is requested
+	RankingProfiles map[string]RankingProfile
+}
+
+// RankingProfile scoring model of search results. The score of a video is
+// textRank * (1 + recencyBoost * 0.5^(age / recencyHalfLifeHours)) * (1 + popularityBoost * ln(1 + viewCount)).
+// Options which are not set are taken from the default ranking options.
+type RankingProfile struct {
+	Function             string   `json:"function,omitempty"`
+	Normalization        *int     `json:"normalization,omitempty"`
+	TitleWeight          *float64 `json:"titleWeight,omitempty"`
+	DescriptionWeight    *float64 `json:"descriptionWeight,omitempty"`
+	RecencyHalfLifeHours *float64 `json:"recencyHalfLifeHours,omitempty"`
+	RecencyBoost         *float64 `json:"recencyBoost,omitempty"`
+	PopularityBoost      *float64 `json:"popularityBoost,omitempty"`
+}
+
+type Webhook struct {
+	// Timeout of a delivery request to a webhook
+	Timeout time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	// MaxAttempts is the number of attempts of a delivery before it is marked as failed
+	MaxAttempts int `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	// PollInterval is the interval at which the deliveries due for a retry are attempted
+	PollInterval time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL"`
+}
+
+type Auth struct {
+	// Enabled requires an API key of a client having the scope of the route for all the routes except the health check
+	Enabled bool `mapstructure:"AUTH_ENABLED"`
+}
+
+type RateLimit struct {
+	// Enabled limits the requests of each API client and each IP with token buckets
+	Enabled bool `mapstructure:"RATE_LIMIT_ENABLED"`
+	// Store of the token buckets, "memory" limits each replica separately and "postgres" shares the limits across the replicas
+	Store string `mapstructure:"RATE_LIMIT_STORE"`
+	// VideosPerMinute and VideosBurst are the budget of the video listing routes
+	VideosPerMinute int `mapstructure:"RATE_LIMIT_VIDEOS_PER_MINUTE"`
+	VideosBurst     int `mapstructure:"RATE_LIMIT_VIDEOS_BURST"`
+	// SearchPerMinute and SearchBurst are the budget of the search routes, which are more expensive
+	SearchPerMinute int `mapstructure:"RATE_LIMIT_SEARCH_PER_MINUTE"`
+	SearchBurst     int `mapstructure:"RATE_LIMIT_SEARCH_BURST"`
+	// TrustedProxies are the IPs and CIDRs of the proxies whose X-Forwarded-For header gives the client IP, which is
+	// the remote address of the request when there are none, and whose X-Forwarded-Proto header gives the scheme of the
+	// links of the feeds
+	TrustedProxies []string `mapstructure:"RATE_LIMIT_TRUSTED_PROXIES"`
+}
+
+type Tracing struct {
+	// Exporter of the spans, "none" disables tracing, "stdout" prints the spans for local debugging and "otlp" sends
+	// them to an OpenTelemetry collector over gRPC
+	Exporter string `mapstructure:"TRACING_EXPORTER"`
+	// OTLPEndpoint is the host and port of the collector, and OTLPInsecure disables TLS to it
+	OTLPEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure bool   `mapstructure:"TRACING_OTLP_INSECURE"`
+	// SampleRatio is the fraction of the traces started by the API which are recorded
+	SampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+}
+
+type Log struct {
+	// Format of the logs, "json" or "text"
+	Format string `mapstructure:"LOG_FORMAT"`
+	// Level is the minimum level of the logs written, "debug", "info", "warn" or "error"
+	Level string `mapstructure:"LOG_LEVEL"`
+}
+
+type Health struct {
+	// CheckTimeout is the time after which a readiness check of a dependency fails
+	CheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	// IngestionMaxAge is the time since the last successful search of the videos after which the ingestion is
+	// reported as failing, it must be longer than the interval of the cron fetching the videos
+	IngestionMaxAge time.Duration `mapstructure:"HEALTH_INGESTION_MAX_AGE"`
+}
+
+type GraphQL struct {
+	// MaxDepth is the maximum nesting depth of the fields of a GraphQL query, the introspection fields are not counted
+	MaxDepth int `mapstructure:"GRAPHQL_MAX_DEPTH"`
+	// MaxComplexity is the maximum complexity of a GraphQL query, where each field costs 1 and the fields of a list
+	// cost as many times as the number of items requested by its first argument
+	MaxComplexity int `mapstructure:"GRAPHQL_MAX_COMPLEXITY"`
+}
+
 type Database struct {
 	Host     string `mapstructure:"DB_HOST"`
 	Port     uint   `mapstructure:"DB_PORT"`
@@ -36,18 +140,19 @@ type Database struct {
 func LoadConfig() (err error) {
 	// set config file's path, name and type
 	viper.SetConfigFile(".env")
+	setDefaults()
 	if err = viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			log.Printf("no config file found. going on...")
+			slog.Warn("no config file found. going on...")
 		} else {
-			log.Printf("error loading config file")
+			slog.Error("error loading config file", "err", err)
 		}
 		return
 	}
 	// load environment variables in the config struct
 	err = viper.Unmarshal(&Conf)
 	if err != nil {
-		log.Printf("error while unmarshaling the config into a Struct: %v\n", err)
+		slog.Error("error while unmarshaling the config into a Struct", "err", err)
 		return
 	}
 	googleAPIKeys := viper.Get("GOOGLE_API_KEYS")
@@ -55,5 +160,76 @@ func LoadConfig() (err error) {
 	Conf.GoogleAPIKeys = strings.Split(googleAPIKeys.(string), ",")
 	// by default set first key as active api key
 	Conf.ActiveGoogleAPIKey = Conf.GoogleAPIKeys[0]
+	// ranking profiles are configured as a JSON object of profile name to ranking options
+	if rankingProfiles := viper.GetString("SEARCH_RANKING_PROFILES"); rankingProfiles != "" {
+		if err = json.Unmarshal([]byte(rankingProfiles), &Conf.Search.RankingProfiles); err != nil {
+			slog.Error("error while unmarshaling the search ranking profiles", "err", err)
+			return
+		}
+	}
+	if err = validateRateLimit(Conf.RateLimit); err != nil {
+		slog.Error("invalid rate limit config", "err", err)
+		return
+	}
 	return
 }
+
+// validateRateLimit returns an error if a budget of the enabled rate limits doesn't refill or hold any token, or if a
+// trusted proxy is neither an IP nor a CIDR
+func validateRateLimit(rateLimit RateLimit) error {
+	if !rateLimit.Enabled {
+		return nil
+	}
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"RATE_LIMIT_VIDEOS_PER_MINUTE", rateLimit.VideosPerMinute},
+		{"RATE_LIMIT_VIDEOS_BURST", rateLimit.VideosBurst},
+		{"RATE_LIMIT_SEARCH_PER_MINUTE", rateLimit.SearchPerMinute},
+		{"RATE_LIMIT_SEARCH_BURST", rateLimit.SearchBurst},
+	}
+	for _, limit := range limits {
+		if limit.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", limit.name, limit.value)
+		}
+	}
+	for _, proxy := range rateLimit.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("RATE_LIMIT_TRUSTED_PROXIES has an invalid IP or CIDR %q", proxy)
+			}
+		}
+	}
+	return nil
+}
+
+// setDefaults sets the default values of optional config variables
+func setDefaults() {
+	viper.SetDefault("GRPC_PORT", 9090)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "30s")
+	viper.SetDefault("FUZZY_SEARCH_MIN_HITS", 5)
+	viper.SetDefault("SEARCH_SUGGEST_TIMEOUT", "150ms")
+	viper.SetDefault("SEARCH_SUGGEST_CACHE_SIZE", 1000)
+	viper.SetDefault("SEARCH_SUGGEST_CACHE_TTL", "1m")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL", "5s")
+	viper.SetDefault("AUTH_ENABLED", false)
+	viper.SetDefault("RATE_LIMIT_ENABLED", false)
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("RATE_LIMIT_VIDEOS_PER_MINUTE", 120)
+	viper.SetDefault("RATE_LIMIT_VIDEOS_BURST", 30)
+	viper.SetDefault("RATE_LIMIT_SEARCH_PER_MINUTE", 30)
+	viper.SetDefault("RATE_LIMIT_SEARCH_BURST", 10)
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "localhost:4317")
+	viper.SetDefault("TRACING_OTLP_INSECURE", true)
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1)
+	viper.SetDefault("LOG_FORMAT", "json")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	viper.SetDefault("HEALTH_INGESTION_MAX_AGE", "10m")
+	viper.SetDefault("GRAPHQL_MAX_DEPTH", 8)
+	viper.SetDefault("GRAPHQL_MAX_COMPLEXITY", 2000)
+}
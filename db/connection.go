@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/Gohelraj/youtube-search-api/config"
+	"github.com/Gohelraj/youtube-search-api/pkg/tracing"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"log"
+	"log/slog"
+	"os"
 	"runtime"
 )
 
@@ -27,13 +30,20 @@ func Connect() (*pgxpool.Pool, error) {
 	// by increasing it.
 	conf.MaxConns = int32(runtime.NumCPU() * 2)
 
+	// the queries are traced from the pgx logs of the queries
+	if tracing.Enabled() {
+		conf.ConnConfig.Logger = tracing.NewQueryLogger()
+		conf.ConnConfig.LogLevel = pgx.LogLevelInfo
+	}
+
 	pool, err := pgxpool.ConnectConfig(context.Background(), conf)
 	if err != nil {
 		return nil, fmt.Errorf("pgx connection error: %w", err)
 	}
 	// verify that the connection works
 	if err := pool.Ping(context.Background()); err != nil {
-		log.Fatal(err)
+		slog.Error("error pinging db", "err", err)
+		os.Exit(1)
 	}
 	return pool, nil
 }
\ No newline at end of file
@@ -0,0 +1,36 @@
+-- migrate:up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- trigram index used by fuzzy search to match misspelled words in video title
+CREATE INDEX IF NOT EXISTS idx_videos_title_trgm ON videos USING GIN (title gin_trgm_ops);
+
+-- vocabulary of words of the stored videos, used to suggest the correct spelling of a search string
+CREATE TABLE IF NOT EXISTS search_words (
+    word VARCHAR(200) PRIMARY KEY,
+    ndoc INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_search_words_word_trgm ON search_words USING GIN (word gin_trgm_ops);
+
+INSERT INTO search_words (word, ndoc)
+SELECT word, ndoc FROM ts_stat('SELECT to_tsvector(''simple'', title || '' '' || coalesce(description, '''')) FROM videos')
+WHERE length(word) BETWEEN 3 AND 200;
+
+CREATE FUNCTION search_words_trigger() RETURNS trigger as $$
+BEGIN
+    INSERT INTO search_words (word)
+    SELECT word FROM unnest(tsvector_to_array(to_tsvector('simple', NEW.title || ' ' || coalesce(NEW.description, '')))) AS word
+    WHERE length(word) BETWEEN 3 AND 200
+    ON CONFLICT (word) DO UPDATE SET ndoc = search_words.ndoc + 1;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER search_words_update AFTER INSERT
+    ON videos FOR EACH ROW EXECUTE PROCEDURE search_words_trigger();
+
+-- migrate:down
+DROP TRIGGER IF EXISTS search_words_update ON videos;
+DROP FUNCTION IF EXISTS search_words_trigger;
+DROP TABLE IF EXISTS search_words;
+DROP INDEX IF EXISTS idx_videos_title_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
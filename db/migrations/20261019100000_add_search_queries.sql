@@ -0,0 +1,11 @@
+-- migrate:up
+-- normalized search strings with the number of times they were searched, used for query autocomplete
+CREATE TABLE IF NOT EXISTS search_queries (
+    query VARCHAR(200) PRIMARY KEY,
+    count INTEGER NOT NULL DEFAULT 1,
+    last_searched_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_search_queries_query_pattern ON search_queries (query varchar_pattern_ops);
+
+-- migrate:down
+DROP TABLE IF EXISTS search_queries;
@@ -0,0 +1,26 @@
+-- migrate:up
+-- language of the video's title and description, and the text search configuration used to index them
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS language VARCHAR(20) NULL;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS text_search_config regconfig NOT NULL DEFAULT 'english';
+
+CREATE OR REPLACE FUNCTION videos_tsvector_trigger() RETURNS trigger as $$
+BEGIN
+    NEW.document_with_weights :=
+        setweight(to_tsvector(NEW.text_search_config, NEW.title), 'A')
+        || setweight(to_tsvector(NEW.text_search_config, coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- migrate:down
+CREATE OR REPLACE FUNCTION videos_tsvector_trigger() RETURNS trigger as $$
+BEGIN
+    NEW.document_with_weights :=
+        setweight(to_tsvector('english', NEW.title), 'A')
+        || setweight(to_tsvector('english', NEW.description), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS text_search_config;
+ALTER TABLE videos DROP COLUMN IF EXISTS language;
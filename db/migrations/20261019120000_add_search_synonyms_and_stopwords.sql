@@ -0,0 +1,43 @@
+-- migrate:up
+-- synonyms of search terms, a search term matches videos containing any of its synonyms
+CREATE TABLE IF NOT EXISTS search_synonyms (
+    id SERIAL PRIMARY KEY,
+    term VARCHAR(100) NOT NULL,
+    synonyms VARCHAR(100)[] NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_synonyms_term ON search_synonyms (term);
+
+-- domain specific stopwords which are neither indexed nor searched
+CREATE TABLE IF NOT EXISTS search_stopwords (
+    word VARCHAR(100) PRIMARY KEY,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION videos_tsvector_trigger() RETURNS trigger as $$
+DECLARE
+    stopword_lexemes text[];
+BEGIN
+    SELECT coalesce(array_agg(lexeme), '{}') INTO stopword_lexemes
+    FROM search_stopwords, unnest(tsvector_to_array(to_tsvector(NEW.text_search_config, word))) AS lexeme;
+    NEW.document_with_weights := ts_delete(
+        setweight(to_tsvector(NEW.text_search_config, NEW.title), 'A')
+        || setweight(to_tsvector(NEW.text_search_config, coalesce(NEW.description, '')), 'B'),
+        stopword_lexemes);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- migrate:down
+CREATE OR REPLACE FUNCTION videos_tsvector_trigger() RETURNS trigger as $$
+BEGIN
+    NEW.document_with_weights :=
+        setweight(to_tsvector(NEW.text_search_config, NEW.title), 'A')
+        || setweight(to_tsvector(NEW.text_search_config, coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS search_stopwords;
+DROP TABLE IF EXISTS search_synonyms;
@@ -0,0 +1,16 @@
+-- migrate:up
+-- channel of the video, its duration and the keyword the video was fetched for, used for search facets
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS channel_id VARCHAR(50) NULL;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS channel_title VARCHAR(200) NULL;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS duration_seconds INTEGER NULL;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS keyword VARCHAR(100) NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_channel_id ON videos (channel_id);
+CREATE INDEX IF NOT EXISTS idx_videos_keyword ON videos (keyword);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_videos_keyword;
+DROP INDEX IF EXISTS idx_videos_channel_id;
+ALTER TABLE videos DROP COLUMN IF EXISTS keyword;
+ALTER TABLE videos DROP COLUMN IF EXISTS duration_seconds;
+ALTER TABLE videos DROP COLUMN IF EXISTS channel_title;
+ALTER TABLE videos DROP COLUMN IF EXISTS channel_id;
@@ -0,0 +1,6 @@
+-- migrate:up
+-- number of views of the video when it was fetched, used to boost popular videos in search
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS view_count BIGINT NULL;
+
+-- migrate:down
+ALTER TABLE videos DROP COLUMN IF EXISTS view_count;
@@ -0,0 +1,34 @@
+-- migrate:up
+-- SimHash fingerprint of the video's title and description, and the cluster of its near-duplicates
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS simhash BIGINT NULL;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS cluster_id INTEGER NULL;
+UPDATE videos SET cluster_id = id WHERE cluster_id IS NULL;
+ALTER TABLE videos ALTER COLUMN cluster_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_cluster_id_published_at ON videos (cluster_id, published_at);
+-- near-duplicate fingerprints share at least one of their 16-bit bands
+CREATE INDEX IF NOT EXISTS idx_videos_simhash_band_0 ON videos ((simhash & 65535));
+CREATE INDEX IF NOT EXISTS idx_videos_simhash_band_1 ON videos (((simhash >> 16) & 65535));
+CREATE INDEX IF NOT EXISTS idx_videos_simhash_band_2 ON videos (((simhash >> 32) & 65535));
+CREATE INDEX IF NOT EXISTS idx_videos_simhash_band_3 ON videos (((simhash >> 48) & 65535));
+
+-- a video which is not a near-duplicate of another video starts its own cluster
+CREATE FUNCTION videos_cluster_trigger() RETURNS trigger as $$
+BEGIN
+    NEW.cluster_id := coalesce(NEW.cluster_id, NEW.id);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cluster_update BEFORE INSERT
+    ON videos FOR EACH ROW EXECUTE PROCEDURE videos_cluster_trigger();
+
+-- migrate:down
+DROP TRIGGER IF EXISTS cluster_update ON videos;
+DROP FUNCTION IF EXISTS videos_cluster_trigger;
+DROP INDEX IF EXISTS idx_videos_simhash_band_3;
+DROP INDEX IF EXISTS idx_videos_simhash_band_2;
+DROP INDEX IF EXISTS idx_videos_simhash_band_1;
+DROP INDEX IF EXISTS idx_videos_simhash_band_0;
+DROP INDEX IF EXISTS idx_videos_cluster_id_published_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS cluster_id;
+ALTER TABLE videos DROP COLUMN IF EXISTS simhash;
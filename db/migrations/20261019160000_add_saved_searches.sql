@@ -0,0 +1,28 @@
+-- migrate:up
+-- searches saved by analysts, which are evaluated against the newly inserted videos
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    query VARCHAR(200) NOT NULL,
+    language VARCHAR(20) NULL,
+    channel_id VARCHAR(50) NULL,
+    keyword VARCHAR(100) NULL,
+    owner VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_owner ON saved_searches (owner);
+
+-- newly inserted videos matching a saved search, seen_at is set once they are returned as new results
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    saved_search_id INTEGER NOT NULL REFERENCES saved_searches (id) ON DELETE CASCADE,
+    video_id INTEGER NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    matched_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    seen_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    PRIMARY KEY (saved_search_id, video_id)
+);
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_unseen ON saved_search_matches (saved_search_id) WHERE seen_at IS NULL;
+
+-- migrate:down
+DROP TABLE IF EXISTS saved_search_matches;
+DROP TABLE IF EXISTS saved_searches;
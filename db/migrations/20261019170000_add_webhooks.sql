@@ -0,0 +1,46 @@
+-- migrate:up
+-- subscriptions of downstream systems to the newly inserted videos, matching the optional query and filters
+CREATE TABLE IF NOT EXISTS webhooks (
+    id SERIAL PRIMARY KEY,
+    url VARCHAR(2000) NOT NULL,
+    secret VARCHAR(200) NOT NULL,
+    query VARCHAR(200) NULL,
+    language VARCHAR(20) NULL,
+    channel_id VARCHAR(50) NULL,
+    keyword VARCHAR(100) NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+-- payloads to deliver to the webhooks, a pending delivery is attempted again at next_attempt_at
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    last_status_code INTEGER NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    delivered_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id_created_at ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- log of the attempts of each delivery
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id SERIAL PRIMARY KEY,
+    delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    status_code INTEGER NULL,
+    error TEXT NULL,
+    duration_ms BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts (delivery_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,15 @@
+-- migrate:up
+-- notifies the listeners of the "videos_inserted" channel of the id of each inserted video once it is committed
+CREATE FUNCTION videos_notify_trigger() RETURNS trigger as $$
+BEGIN
+    PERFORM pg_notify('videos_inserted', NEW.id::text);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER videos_notify AFTER INSERT
+    ON videos FOR EACH ROW EXECUTE PROCEDURE videos_notify_trigger();
+
+-- migrate:down
+DROP TRIGGER IF EXISTS videos_notify ON videos;
+DROP FUNCTION IF EXISTS videos_notify_trigger;
@@ -0,0 +1,18 @@
+-- migrate:up
+-- clients of the API authenticated by their key, of which only the SHA-256 hash is stored. key_prefix is the start of
+-- the key which identifies it to the admins, and a revoked client can't authenticate anymore.
+CREATE TABLE IF NOT EXISTS api_clients (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_clients_key_hash ON api_clients (key_hash);
+
+-- migrate:down
+DROP TABLE IF EXISTS api_clients;
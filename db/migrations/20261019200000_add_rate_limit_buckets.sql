@@ -0,0 +1,27 @@
+-- migrate:up
+-- token buckets of the rate limits shared by the API replicas, which don't need to survive a crash
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(200) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+-- refills the bucket of the key at rate tokens per second up to burst tokens, and takes a token from it if it has one
+CREATE FUNCTION rate_limit_take(bucket_key VARCHAR, rate DOUBLE PRECISION, burst DOUBLE PRECISION, OUT allowed BOOLEAN, OUT remaining_tokens DOUBLE PRECISION) as $$
+DECLARE
+    taken_at TIMESTAMP WITHOUT TIME ZONE := clock_timestamp() AT TIME ZONE 'UTC';
+BEGIN
+    INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES (bucket_key, burst, taken_at) ON CONFLICT (key) DO NOTHING;
+    SELECT least(burst, bucket.tokens + greatest(0, extract(epoch FROM taken_at - bucket.updated_at)) * rate) INTO remaining_tokens
+    FROM rate_limit_buckets AS bucket WHERE bucket.key = bucket_key FOR UPDATE;
+    allowed := remaining_tokens >= 1;
+    IF allowed THEN
+        remaining_tokens := remaining_tokens - 1;
+    END IF;
+    UPDATE rate_limit_buckets SET tokens = remaining_tokens, updated_at = taken_at WHERE key = bucket_key;
+END
+$$ LANGUAGE plpgsql;
+
+-- migrate:down
+DROP FUNCTION IF EXISTS rate_limit_take;
+DROP TABLE IF EXISTS rate_limit_buckets;
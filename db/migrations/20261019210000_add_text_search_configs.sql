@@ -0,0 +1,10 @@
+-- migrate:up
+-- the videos of the languages whose text search configuration was missing were indexed without stemming, updating
+-- their configuration fires the tsvupdate trigger which reindexes them
+UPDATE videos SET text_search_config = configs.config::regconfig
+FROM (VALUES ('ca', 'catalan'), ('eu', 'basque'), ('hi', 'hindi'), ('hy', 'armenian'), ('sr', 'serbian'), ('yi', 'yiddish')) AS configs(language, config)
+WHERE lower(split_part(replace(videos.language, '_', '-'), '-', 1)) = configs.language AND videos.text_search_config = 'simple'::regconfig;
+
+-- migrate:down
+UPDATE videos SET text_search_config = 'simple'
+WHERE text_search_config IN ('catalan'::regconfig, 'basque'::regconfig, 'hindi'::regconfig, 'armenian'::regconfig, 'serbian'::regconfig, 'yiddish'::regconfig);
@@ -9,6 +9,87 @@ SET xmloption = content;
 SET client_min_messages = warning;
 SET row_security = off;
 
+--
+-- Name: pg_trgm; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION pg_trgm; Type: COMMENT; Schema: -; Owner: -
+--
+
+COMMENT ON EXTENSION pg_trgm IS 'text similarity measurement and index searching based on trigrams';
+
+
+--
+-- Name: rate_limit_take(character varying, double precision, double precision); Type: FUNCTION; Schema: public; Owner: -
+--
+
+CREATE FUNCTION public.rate_limit_take(bucket_key character varying, rate double precision, burst double precision, OUT allowed boolean, OUT remaining_tokens double precision) RETURNS record
+    LANGUAGE plpgsql
+    AS $$
+DECLARE
+    taken_at TIMESTAMP WITHOUT TIME ZONE := clock_timestamp() AT TIME ZONE 'UTC';
+BEGIN
+    INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES (bucket_key, burst, taken_at) ON CONFLICT (key) DO NOTHING;
+    SELECT least(burst, bucket.tokens + greatest(0, extract(epoch FROM taken_at - bucket.updated_at)) * rate) INTO remaining_tokens
+    FROM rate_limit_buckets AS bucket WHERE bucket.key = bucket_key FOR UPDATE;
+    allowed := remaining_tokens >= 1;
+    IF allowed THEN
+        remaining_tokens := remaining_tokens - 1;
+    END IF;
+    UPDATE rate_limit_buckets SET tokens = remaining_tokens, updated_at = taken_at WHERE key = bucket_key;
+END
+$$;
+
+
+--
+-- Name: search_words_trigger(); Type: FUNCTION; Schema: public; Owner: -
+--
+
+CREATE FUNCTION public.search_words_trigger() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    INSERT INTO search_words (word)
+    SELECT word FROM unnest(tsvector_to_array(to_tsvector('simple', NEW.title || ' ' || coalesce(NEW.description, '')))) AS word
+    WHERE length(word) BETWEEN 3 AND 200
+    ON CONFLICT (word) DO UPDATE SET ndoc = search_words.ndoc + 1;
+    RETURN NULL;
+END
+$$;
+
+
+--
+-- Name: videos_cluster_trigger(); Type: FUNCTION; Schema: public; Owner: -
+--
+
+CREATE FUNCTION public.videos_cluster_trigger() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.cluster_id := coalesce(NEW.cluster_id, NEW.id);
+    RETURN NEW;
+END
+$$;
+
+
+--
+-- Name: videos_notify_trigger(); Type: FUNCTION; Schema: public; Owner: -
+--
+
+CREATE FUNCTION public.videos_notify_trigger() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    PERFORM pg_notify('videos_inserted', NEW.id::text);
+    RETURN NULL;
+END
+$$;
+
+
 --
 -- Name: videos_tsvector_trigger(); Type: FUNCTION; Schema: public; Owner: -
 --
@@ -16,10 +97,15 @@ SET row_security = off;
 CREATE FUNCTION public.videos_tsvector_trigger() RETURNS trigger
     LANGUAGE plpgsql
     AS $$
+DECLARE
+    stopword_lexemes text[];
 BEGIN
-    NEW.document_with_weights :=
-        setweight(to_tsvector('english', NEW.title), 'A')
-        || setweight(to_tsvector('english', NEW.description), 'B');
+    SELECT coalesce(array_agg(lexeme), '{}') INTO stopword_lexemes
+    FROM search_stopwords, unnest(tsvector_to_array(to_tsvector(NEW.text_search_config, word))) AS lexeme;
+    NEW.document_with_weights := ts_delete(
+        setweight(to_tsvector(NEW.text_search_config, NEW.title), 'A')
+        || setweight(to_tsvector(NEW.text_search_config, coalesce(NEW.description, '')), 'B'),
+        stopword_lexemes);
     RETURN NEW;
 END
 $$;
@@ -29,6 +115,43 @@ SET default_tablespace = '';
 
 SET default_with_oids = false;
 
+--
+-- Name: api_clients; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.api_clients (
+    id integer NOT NULL,
+    name character varying(100) NOT NULL,
+    key_prefix character varying(20) NOT NULL,
+    key_hash character varying(64) NOT NULL,
+    scopes text[] NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL,
+    last_used_at timestamp without time zone,
+    revoked_at timestamp without time zone
+);
+
+
+--
+-- Name: api_clients_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.api_clients_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: api_clients_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.api_clients_id_seq OWNED BY public.api_clients.id;
+
+
 --
 -- Name: page_tokens; Type: TABLE; Schema: public; Owner: -
 --
@@ -62,6 +185,66 @@ CREATE SEQUENCE public.page_tokens_id_seq
 ALTER SEQUENCE public.page_tokens_id_seq OWNED BY public.page_tokens.id;
 
 
+--
+-- Name: rate_limit_buckets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE UNLOGGED TABLE public.rate_limit_buckets (
+    key character varying(200) NOT NULL,
+    tokens double precision NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: saved_search_matches; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.saved_search_matches (
+    saved_search_id integer NOT NULL,
+    video_id integer NOT NULL,
+    matched_at timestamp without time zone NOT NULL,
+    seen_at timestamp without time zone
+);
+
+
+--
+-- Name: saved_searches; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.saved_searches (
+    id integer NOT NULL,
+    name character varying(100) NOT NULL,
+    query character varying(200) NOT NULL,
+    language character varying(20),
+    channel_id character varying(50),
+    keyword character varying(100),
+    owner character varying(100) NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: saved_searches_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.saved_searches_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: saved_searches_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.saved_searches_id_seq OWNED BY public.saved_searches.id;
+
+
 --
 -- Name: schema_migrations; Type: TABLE; Schema: public; Owner: -
 --
@@ -71,6 +254,70 @@ CREATE TABLE public.schema_migrations (
 );
 
 
+--
+-- Name: search_queries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.search_queries (
+    query character varying(200) NOT NULL,
+    count integer DEFAULT 1 NOT NULL,
+    last_searched_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: search_stopwords; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.search_stopwords (
+    word character varying(100) NOT NULL,
+    created_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: search_synonyms; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.search_synonyms (
+    id integer NOT NULL,
+    term character varying(100) NOT NULL,
+    synonyms character varying(100)[] NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: search_synonyms_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.search_synonyms_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: search_synonyms_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.search_synonyms_id_seq OWNED BY public.search_synonyms.id;
+
+
+--
+-- Name: search_words; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.search_words (
+    word character varying(200) NOT NULL,
+    ndoc integer DEFAULT 1 NOT NULL
+);
+
+
 --
 -- Name: videos; Type: TABLE; Schema: public; Owner: -
 --
@@ -84,7 +331,16 @@ CREATE TABLE public.videos (
     created_at timestamp without time zone NOT NULL,
     updated_at timestamp without time zone NOT NULL,
     thumbnail_url character varying(500) NOT NULL,
-    document_with_weights tsvector NOT NULL
+    document_with_weights tsvector NOT NULL,
+    language character varying(20),
+    text_search_config regconfig DEFAULT 'english'::regconfig NOT NULL,
+    channel_id character varying(50),
+    channel_title character varying(200),
+    duration_seconds integer,
+    keyword character varying(100),
+    view_count bigint,
+    simhash bigint,
+    cluster_id integer NOT NULL
 );
 
 
@@ -108,6 +364,123 @@ CREATE SEQUENCE public.videos_id_seq
 ALTER SEQUENCE public.videos_id_seq OWNED BY public.videos.id;
 
 
+--
+-- Name: webhook_deliveries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.webhook_deliveries (
+    id integer NOT NULL,
+    webhook_id integer NOT NULL,
+    event character varying(50) NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(20) DEFAULT 'pending'::character varying NOT NULL,
+    attempt_count integer DEFAULT 0 NOT NULL,
+    next_attempt_at timestamp without time zone,
+    last_status_code integer,
+    last_error text,
+    created_at timestamp without time zone NOT NULL,
+    delivered_at timestamp without time zone
+);
+
+
+--
+-- Name: webhook_deliveries_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.webhook_deliveries_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: webhook_deliveries_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.webhook_deliveries_id_seq OWNED BY public.webhook_deliveries.id;
+
+
+--
+-- Name: webhook_delivery_attempts; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.webhook_delivery_attempts (
+    id integer NOT NULL,
+    delivery_id integer NOT NULL,
+    attempted_at timestamp without time zone NOT NULL,
+    status_code integer,
+    error text,
+    duration_ms bigint NOT NULL
+);
+
+
+--
+-- Name: webhook_delivery_attempts_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.webhook_delivery_attempts_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: webhook_delivery_attempts_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.webhook_delivery_attempts_id_seq OWNED BY public.webhook_delivery_attempts.id;
+
+
+--
+-- Name: webhooks; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.webhooks (
+    id integer NOT NULL,
+    url character varying(2000) NOT NULL,
+    secret character varying(200) NOT NULL,
+    query character varying(200),
+    language character varying(20),
+    channel_id character varying(50),
+    keyword character varying(100),
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: webhooks_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.webhooks_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: webhooks_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.webhooks_id_seq OWNED BY public.webhooks.id;
+
+
+--
+-- Name: api_clients id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.api_clients ALTER COLUMN id SET DEFAULT nextval('public.api_clients_id_seq'::regclass);
+
+
 --
 -- Name: page_tokens id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -115,6 +488,20 @@ ALTER SEQUENCE public.videos_id_seq OWNED BY public.videos.id;
 ALTER TABLE ONLY public.page_tokens ALTER COLUMN id SET DEFAULT nextval('public.page_tokens_id_seq'::regclass);
 
 
+--
+-- Name: saved_searches id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.saved_searches ALTER COLUMN id SET DEFAULT nextval('public.saved_searches_id_seq'::regclass);
+
+
+--
+-- Name: search_synonyms id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.search_synonyms ALTER COLUMN id SET DEFAULT nextval('public.search_synonyms_id_seq'::regclass);
+
+
 --
 -- Name: videos id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -122,6 +509,35 @@ ALTER TABLE ONLY public.page_tokens ALTER COLUMN id SET DEFAULT nextval('public.
 ALTER TABLE ONLY public.videos ALTER COLUMN id SET DEFAULT nextval('public.videos_id_seq'::regclass);
 
 
+--
+-- Name: webhook_deliveries id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries ALTER COLUMN id SET DEFAULT nextval('public.webhook_deliveries_id_seq'::regclass);
+
+
+--
+-- Name: webhook_delivery_attempts id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_delivery_attempts ALTER COLUMN id SET DEFAULT nextval('public.webhook_delivery_attempts_id_seq'::regclass);
+
+
+--
+-- Name: webhooks id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhooks ALTER COLUMN id SET DEFAULT nextval('public.webhooks_id_seq'::regclass);
+
+
+--
+-- Name: api_clients api_clients_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.api_clients
+    ADD CONSTRAINT api_clients_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: page_tokens page_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -130,6 +546,30 @@ ALTER TABLE ONLY public.page_tokens
     ADD CONSTRAINT page_tokens_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: rate_limit_buckets rate_limit_buckets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.rate_limit_buckets
+    ADD CONSTRAINT rate_limit_buckets_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: saved_search_matches saved_search_matches_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.saved_search_matches
+    ADD CONSTRAINT saved_search_matches_pkey PRIMARY KEY (saved_search_id, video_id);
+
+
+--
+-- Name: saved_searches saved_searches_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.saved_searches
+    ADD CONSTRAINT saved_searches_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: schema_migrations schema_migrations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -138,6 +578,38 @@ ALTER TABLE ONLY public.schema_migrations
     ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
 
 
+--
+-- Name: search_queries search_queries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.search_queries
+    ADD CONSTRAINT search_queries_pkey PRIMARY KEY (query);
+
+
+--
+-- Name: search_stopwords search_stopwords_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.search_stopwords
+    ADD CONSTRAINT search_stopwords_pkey PRIMARY KEY (word);
+
+
+--
+-- Name: search_synonyms search_synonyms_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.search_synonyms
+    ADD CONSTRAINT search_synonyms_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: search_words search_words_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.search_words
+    ADD CONSTRAINT search_words_pkey PRIMARY KEY (word);
+
+
 --
 -- Name: videos videos_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -146,6 +618,37 @@ ALTER TABLE ONLY public.videos
     ADD CONSTRAINT videos_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: webhook_deliveries webhook_deliveries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_delivery_attempts webhook_delivery_attempts_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_delivery_attempts
+    ADD CONSTRAINT webhook_delivery_attempts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhooks webhooks_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhooks
+    ADD CONSTRAINT webhooks_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: idx_api_clients_key_hash; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_api_clients_key_hash ON public.api_clients USING btree (key_hash);
+
+
 --
 -- Name: idx_page_tokens_next_page_token_is_used; Type: INDEX; Schema: public; Owner: -
 --
@@ -153,6 +656,55 @@ ALTER TABLE ONLY public.videos
 CREATE INDEX idx_page_tokens_next_page_token_is_used ON public.page_tokens USING btree (next_page_token, is_used);
 
 
+--
+-- Name: idx_saved_search_matches_unseen; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_saved_search_matches_unseen ON public.saved_search_matches USING btree (saved_search_id) WHERE (seen_at IS NULL);
+
+
+--
+-- Name: idx_saved_searches_owner; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_saved_searches_owner ON public.saved_searches USING btree (owner);
+
+
+--
+-- Name: idx_search_queries_query_pattern; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_search_queries_query_pattern ON public.search_queries USING btree (query varchar_pattern_ops);
+
+
+--
+-- Name: idx_search_synonyms_term; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_search_synonyms_term ON public.search_synonyms USING btree (term);
+
+
+--
+-- Name: idx_search_words_word_trgm; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_search_words_word_trgm ON public.search_words USING gin (word public.gin_trgm_ops);
+
+
+--
+-- Name: idx_videos_channel_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_channel_id ON public.videos USING btree (channel_id);
+
+
+--
+-- Name: idx_videos_cluster_id_published_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_cluster_id_published_at ON public.videos USING btree (cluster_id, published_at);
+
+
 --
 -- Name: idx_videos_document_with_weights; Type: INDEX; Schema: public; Owner: -
 --
@@ -160,6 +712,13 @@ CREATE INDEX idx_page_tokens_next_page_token_is_used ON public.page_tokens USING
 CREATE INDEX idx_videos_document_with_weights ON public.videos USING gin (document_with_weights);
 
 
+--
+-- Name: idx_videos_keyword; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_keyword ON public.videos USING btree (keyword);
+
+
 --
 -- Name: idx_videos_published_at; Type: INDEX; Schema: public; Owner: -
 --
@@ -167,6 +726,34 @@ CREATE INDEX idx_videos_document_with_weights ON public.videos USING gin (docume
 CREATE INDEX idx_videos_published_at ON public.videos USING btree (published_at);
 
 
+--
+-- Name: idx_videos_simhash_band_0; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_simhash_band_0 ON public.videos USING btree (((simhash & (65535)::bigint)));
+
+
+--
+-- Name: idx_videos_simhash_band_1; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_simhash_band_1 ON public.videos USING btree (((simhash >> 16) & (65535)::bigint));
+
+
+--
+-- Name: idx_videos_simhash_band_2; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_simhash_band_2 ON public.videos USING btree (((simhash >> 32) & (65535)::bigint));
+
+
+--
+-- Name: idx_videos_simhash_band_3; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_simhash_band_3 ON public.videos USING btree (((simhash >> 48) & (65535)::bigint));
+
+
 --
 -- Name: idx_videos_title_description_index; Type: INDEX; Schema: public; Owner: -
 --
@@ -174,6 +761,34 @@ CREATE INDEX idx_videos_published_at ON public.videos USING btree (published_at)
 CREATE INDEX idx_videos_title_description_index ON public.videos USING btree (title, description);
 
 
+--
+-- Name: idx_videos_title_trgm; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_videos_title_trgm ON public.videos USING gin (title public.gin_trgm_ops);
+
+
+--
+-- Name: idx_webhook_deliveries_pending; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_webhook_deliveries_pending ON public.webhook_deliveries USING btree (next_attempt_at) WHERE ((status)::text = 'pending'::text);
+
+
+--
+-- Name: idx_webhook_deliveries_webhook_id_created_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_webhook_deliveries_webhook_id_created_at ON public.webhook_deliveries USING btree (webhook_id, created_at);
+
+
+--
+-- Name: idx_webhook_delivery_attempts_delivery_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery_id ON public.webhook_delivery_attempts USING btree (delivery_id);
+
+
 --
 -- Name: page_tokens_next_page_token_idx; Type: INDEX; Schema: public; Owner: -
 --
@@ -188,6 +803,20 @@ CREATE UNIQUE INDEX page_tokens_next_page_token_idx ON public.page_tokens USING
 CREATE UNIQUE INDEX videos_youtube_id_idx ON public.videos USING btree (youtube_id);
 
 
+--
+-- Name: videos cluster_update; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER cluster_update BEFORE INSERT ON public.videos FOR EACH ROW EXECUTE PROCEDURE public.videos_cluster_trigger();
+
+
+--
+-- Name: videos search_words_update; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER search_words_update AFTER INSERT ON public.videos FOR EACH ROW EXECUTE PROCEDURE public.search_words_trigger();
+
+
 --
 -- Name: videos tsvupdate; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -195,6 +824,45 @@ CREATE UNIQUE INDEX videos_youtube_id_idx ON public.videos USING btree (youtube_
 CREATE TRIGGER tsvupdate BEFORE INSERT OR UPDATE ON public.videos FOR EACH ROW EXECUTE PROCEDURE public.videos_tsvector_trigger();
 
 
+--
+-- Name: videos videos_notify; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER videos_notify AFTER INSERT ON public.videos FOR EACH ROW EXECUTE PROCEDURE public.videos_notify_trigger();
+
+
+--
+-- Name: saved_search_matches saved_search_matches_saved_search_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.saved_search_matches
+    ADD CONSTRAINT saved_search_matches_saved_search_id_fkey FOREIGN KEY (saved_search_id) REFERENCES public.saved_searches(id) ON DELETE CASCADE;
+
+
+--
+-- Name: saved_search_matches saved_search_matches_video_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.saved_search_matches
+    ADD CONSTRAINT saved_search_matches_video_id_fkey FOREIGN KEY (video_id) REFERENCES public.videos(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_webhook_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_webhook_id_fkey FOREIGN KEY (webhook_id) REFERENCES public.webhooks(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_delivery_attempts webhook_delivery_attempts_delivery_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_delivery_attempts
+    ADD CONSTRAINT webhook_delivery_attempts_delivery_id_fkey FOREIGN KEY (delivery_id) REFERENCES public.webhook_deliveries(id) ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -205,4 +873,17 @@ CREATE TRIGGER tsvupdate BEFORE INSERT OR UPDATE ON public.videos FOR EACH ROW E
 --
 
 INSERT INTO public.schema_migrations (version) VALUES
-    ('20220729152134');
+    ('20220729152134'),
+    ('20261019090000'),
+    ('20261019100000'),
+    ('20261019110000'),
+    ('20261019120000'),
+    ('20261019130000'),
+    ('20261019140000'),
+    ('20261019150000'),
+    ('20261019160000'),
+    ('20261019170000'),
+    ('20261019180000'),
+    ('20261019190000'),
+    ('20261019200000'),
+    ('20261019210000');
@@ -1,39 +1,122 @@
 package error
 
 import (
-	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
-// Custom errors
+// Custom errors, their codes are stable and can be relied on by the clients
 var (
-	ErrInvalidValueInLimit  = generateError(http.StatusBadRequest, "invalid value in limit")
-	ErrInvalidValueInOffset = generateError(http.StatusBadRequest, "invalid value in offset")
-	ErrSearchStringRequired = generateError(http.StatusBadRequest, "searchString is required in request body")
-	ErrLimitExceeded        = generateError(http.StatusBadRequest, "limit must be less than 100")
+	ErrInvalidValueInLimit    = generateValidationError("invalid_limit", "limit", "invalid value in limit")
+	ErrInvalidValueInOffset   = generateValidationError("invalid_offset", "offset", "invalid value in offset")
+	ErrInvalidValueInCollapse = generateValidationError("invalid_collapse", "collapse", "invalid value in collapse")
+	ErrSearchStringRequired   = generateValidationError("search_string_required", "searchString", "searchString is required in request body")
+	ErrLimitExceeded          = generateValidationError("limit_exceeded", "limit", "limit must be less than 100")
+
+	ErrVideoNotFound          = generateError(http.StatusNotFound, "video_not_found", "video not found")
+	ErrInvalidPublishedAfter  = generateValidationError("invalid_published_after", "publishedAfter", "publishedAfter must be an RFC 3339 date time")
+	ErrInvalidPublishedBefore = generateValidationError("invalid_published_before", "publishedBefore", "publishedBefore must be an RFC 3339 date time")
+
+	ErrInvalidHighlightSelector  = generateValidationError("invalid_highlight_selector", "highlight", "highlight startSel and stopSel must be at most 20 characters and must not contain double quotes")
+	ErrInvalidHighlightFragments = generateValidationError("invalid_highlight_fragments", "highlight.maxFragments", "highlight maxFragments must be between 0 and 10")
+
+	ErrQueryRequired        = generateValidationError("query_required", "q", "q is required in query params")
+	ErrSuggestLimitExceeded = generateValidationError("suggest_limit_exceeded", "limit", "limit must be less than 10")
+
+	ErrUnsupportedLanguage = generateValidationError("unsupported_language", "language", "language is not supported for search")
+	ErrInvalidFacet        = generateValidationError("invalid_facet", "facets", "facets must be one of channel, publishMonth, duration and keyword")
+
+	ErrUnknownRankingProfile    = generateValidationError("unknown_ranking_profile", "ranking.profile", "ranking profile is not configured")
+	ErrInvalidRankFunction      = generateValidationError("invalid_ranking_function", "ranking.function", "ranking function must be ts_rank or ts_rank_cd")
+	ErrInvalidRankNormalization = generateValidationError("invalid_ranking_normalization", "ranking.normalization", "ranking normalization must be between 0 and 63")
+	ErrInvalidRankWeight        = generateValidationError("invalid_ranking_weight", "ranking", "ranking titleWeight and descriptionWeight must be between 0 and 1")
+	ErrInvalidRankBoost         = generateValidationError("invalid_ranking_boost", "ranking", "ranking recencyHalfLifeHours must be greater than 0 and boosts must not be negative")
+
+	ErrInvalidID            = generateValidationError("invalid_id", "id", "invalid value in id")
+	ErrInvalidSynonymTerm   = generateValidationError("invalid_synonym_term", "term", "term must have 1 to 4 words and at most 100 characters")
+	ErrInvalidSynonyms      = generateValidationError("invalid_synonyms", "synonyms", "synonyms must have at least one synonym other than the term, each of at most 100 characters")
+	ErrSynonymNotFound      = generateError(http.StatusNotFound, "synonym_not_found", "synonym not found")
+	ErrSynonymAlreadyExists = generateError(http.StatusConflict, "synonym_already_exists", "synonyms of the term already exist")
+	ErrInvalidStopword      = generateValidationError("invalid_stopword", "word", "word must be a single word of at most 100 characters")
+	ErrStopwordNotFound     = generateError(http.StatusNotFound, "stopword_not_found", "stopword not found")
+
+	ErrInvalidSavedSearchName  = generateValidationError("invalid_saved_search_name", "name", "name is required and must be at most 100 characters")
+	ErrInvalidSavedSearchQuery = generateValidationError("invalid_saved_search_query", "query", "query is required and must be at most 200 characters")
+	ErrInvalidSavedSearchOwner = generateValidationError("invalid_saved_search_owner", "owner", "owner is required and must be at most 100 characters")
+	ErrInvalidVideoFilters     = generateValidationError("invalid_video_filters", "filters", "filters channelId must be at most 50 characters and keyword at most 100 characters")
+	ErrSavedSearchNotFound     = generateError(http.StatusNotFound, "saved_search_not_found", "saved search not found")
+
+	ErrInvalidWebhookURL       = generateValidationError("invalid_webhook_url", "url", "url must be an absolute http or https URL of at most 2000 characters")
+	ErrInvalidWebhookSecret    = generateValidationError("invalid_webhook_secret", "secret", "secret must be 16 to 200 characters")
+	ErrInvalidWebhookQuery     = generateValidationError("invalid_webhook_query", "query", "query must be at most 200 characters")
+	ErrWebhookNotFound         = generateError(http.StatusNotFound, "webhook_not_found", "webhook not found")
+	ErrWebhookDeliveryNotFound = generateError(http.StatusNotFound, "webhook_delivery_not_found", "webhook delivery not found")
+
+	ErrInvalidQuery        = generateValidationError("invalid_query", "q", "q must be at most 200 characters")
+	ErrInvalidLastEventID  = generateValidationError("invalid_last_event_id", "Last-Event-ID", "Last-Event-ID must be the id of a received event")
+	ErrInvalidExportFormat = generateValidationError("invalid_export_format", "format", "format must be ndjson or csv")
+
+	ErrUnauthorized           = generateError(http.StatusUnauthorized, "unauthorized", "a valid API key is required in the Authorization: Bearer or X-API-Key header")
+	ErrForbidden              = generateError(http.StatusForbidden, "forbidden", "the API key doesn't have the scope required by this route")
+	ErrRateLimited            = generateError(http.StatusTooManyRequests, "rate_limited", "rate limit exceeded, retry after the number of seconds in the Retry-After header")
+	ErrInvalidAPIClientName   = generateValidationError("invalid_api_client_name", "name", "name is required and must be at most 100 characters")
+	ErrInvalidAPIClientScopes = generateValidationError("invalid_api_client_scopes", "scopes", "scopes must have at least one of read, search and admin")
+	ErrAPIClientNotFound      = generateError(http.StatusNotFound, "api_client_not_found", "API client not found")
+	ErrAPIClientRevoked       = generateError(http.StatusConflict, "api_client_revoked", "API client is revoked")
+
+	ErrInvalidImportFormat  = generateValidationError("invalid_import_format", "format", "format must be ndjson or csv")
+	ErrInvalidImportFile    = generateError(http.StatusBadRequest, "invalid_import_file", "CSV files must start with a header row having the youtube_id column")
+	ErrInvalidValueInEnrich = generateValidationError("invalid_enrich", "enrich", "invalid value in enrich")
+
+	ErrInvalidDeliveryID = generateValidationError("invalid_delivery_id", "deliveryId", "invalid value in deliveryId")
+
+	ErrGraphQLQueryRequired   = generateValidationError("graphql_query_required", "query", "query is required")
+	ErrGraphQLQueryTooDeep    = generateError(http.StatusBadRequest, "graphql_query_too_deep", "query is nested deeper than the maximum depth")
+	ErrGraphQLQueryTooComplex = generateError(http.StatusBadRequest, "graphql_query_too_complex", "query is more complex than the maximum complexity, request fewer items or fields")
+	ErrInvalidFirst           = generateValidationError("invalid_first", "first", "first must be between 1 and 100")
+	ErrInvalidCursor          = generateValidationError("invalid_cursor", "after", "after must be the cursor of a returned item")
+	ErrSearchQueryRequired    = generateValidationError("search_query_required", "query", "query is required")
+
+	ErrYoutubeIDRequired = generateValidationError("youtube_id_required", "youtube_id", "youtube_id is required")
+	ErrInvalidAfterID    = generateValidationError("invalid_after_id", "after_id", "after_id must be the id of a received video")
+
+	// Generic errors, which the errors of the request body binding and of the database are mapped to
+	ErrInvalidRequestBody = generateError(http.StatusBadRequest, "invalid_request_body", "request body must be valid JSON")
+	ErrInvalidValue       = generateError(http.StatusBadRequest, "invalid_value", "a value is invalid")
+	ErrRouteNotFound      = generateError(http.StatusNotFound, "route_not_found", "route not found")
+	ErrNotFound           = generateError(http.StatusNotFound, "not_found", "resource not found")
+	ErrConflict           = generateError(http.StatusConflict, "conflict", "resource conflicts with an existing one")
+	ErrTimeout            = generateError(http.StatusServiceUnavailable, "timeout", "request timed out, retry later")
+	ErrShuttingDown       = generateError(http.StatusServiceUnavailable, "shutting_down", "server is shutting down, retry later")
+	ErrInternal           = generateError(http.StatusInternalServerError, "internal_error", "internal error, report it with the request id if it persists")
 )
 
+// Error is an error of the API with its HTTP status code and stable machine-readable code. Field is the request
+// field, query param or path param which is invalid for validation errors.
 type Error struct {
-	HttpStatusCode int    `json:"code,omitempty"`
-	ErrMessage     string `json:"message"`
+	HttpStatusCode int
+	Code           string
+	ErrMessage     string
+	Field          string
 }
 
 func (e Error) Error() string {
 	return e.ErrMessage
 }
 
-func generateError(httpErrCode int, msg string) error {
+func generateError(httpErrCode int, code string, msg string) error {
 	return &Error{
 		HttpStatusCode: httpErrCode,
+		Code:           code,
 		ErrMessage:     msg,
 	}
 }
 
-// SendError write error to response with status code and message
-func SendError(c *gin.Context, err error) {
-	if customErr, ok := err.(*Error); ok {
-		c.JSON(customErr.HttpStatusCode, gin.H{"code": customErr.HttpStatusCode, "error": customErr.ErrMessage})
-	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "error": err.Error()})
+// generateValidationError generates a 400 Bad Request error of an invalid field
+func generateValidationError(code string, field string, msg string) error {
+	return &Error{
+		HttpStatusCode: http.StatusBadRequest,
+		Code:           code,
+		ErrMessage:     msg,
+		Field:          field,
 	}
 }
@@ -0,0 +1,159 @@
+package error
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ProblemContentType is the content type of the error responses, see https://www.rfc-editor.org/rfc/rfc7807
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix prefixes the code of the errors in the type URI of the problems
+const ProblemTypePrefix = "urn:youtube-search-api:problem:"
+
+// Problem is the RFC 7807 problem details of an error response
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is the error of an invalid field of a validation problem
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SendError write error to response as problem details. The errors which aren't custom errors are mapped to a custom
+// error with a safe message, and are logged when they are internal errors so that they are never exposed.
+func SendError(c *gin.Context, err error) {
+	customErr := ToError(c.Request.Context(), err)
+	if c.Writer.Written() {
+		// the response of the streaming routes is already being written
+		slog.WarnContext(c.Request.Context(), "error after response is written", "err", err)
+		return
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(customErr.HttpStatusCode, NewProblem(customErr, c.Request.URL.Path, logging.RequestID(c.Request.Context())))
+}
+
+// NewProblem returns the problem details of the custom error occurred for the given path and request id
+func NewProblem(err *Error, instance string, requestID string) Problem {
+	problem := Problem{
+		Type:      ProblemTypePrefix + err.Code,
+		Title:     http.StatusText(err.HttpStatusCode),
+		Status:    err.HttpStatusCode,
+		Detail:    err.ErrMessage,
+		Instance:  instance,
+		Code:      err.Code,
+		RequestID: requestID,
+	}
+	if err.Field != "" {
+		problem.Errors = []FieldError{{Field: err.Field, Code: err.Code, Message: err.ErrMessage}}
+	}
+	return problem
+}
+
+// ToError returns the custom error of the error, the request body binding and database errors are mapped to a custom
+// error and the other errors to ErrInternal after logging them
+func ToError(ctx context.Context, err error) *Error {
+	var customErr *Error
+	if errors.As(err, &customErr) {
+		return customErr
+	}
+	if mapped := bindingError(err); mapped != nil {
+		return mapped
+	}
+	if mapped := databaseError(err); mapped != nil {
+		slog.WarnContext(ctx, "database error", "err", err)
+		return mapped
+	}
+	slog.ErrorContext(ctx, "internal error", "err", err)
+	return ErrInternal.(*Error)
+}
+
+// bindingError maps the errors of decoding a JSON request body, nil is returned for the other errors
+func bindingError(err error) *Error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &syntaxErr):
+		return ErrInvalidRequestBody.(*Error)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return &Error{
+			HttpStatusCode: http.StatusBadRequest,
+			Code:           "invalid_type",
+			ErrMessage:     fmt.Sprintf("%s must be %s", field, jsonTypeName(typeErr.Type)),
+			Field:          field,
+		}
+	}
+	return nil
+}
+
+// jsonTypeName returns the name of the JSON type which is decoded to the Go type
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// databaseError maps the database errors which aren't internal errors, nil is returned for the other errors.
+// The messages of the database errors may have the queries and data, so they are never exposed.
+func databaseError(err error) *Error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound.(*Error)
+	}
+	if errors.Is(err, context.Canceled) || pgconn.Timeout(err) {
+		return ErrTimeout.(*Error)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch {
+	case pgErr.Code == "57014": // query_canceled, by the statement timeout
+		return ErrTimeout.(*Error)
+	case strings.HasPrefix(pgErr.Code, "23"): // integrity constraint violation
+		return ErrConflict.(*Error)
+	case strings.HasPrefix(pgErr.Code, "22"): // data exception
+		return ErrInvalidValue.(*Error)
+	}
+	return nil
+}
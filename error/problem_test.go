@@ -0,0 +1,97 @@
+package error
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sendError(t *testing.T, err error) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/videos", nil)
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), "request-1"))
+	SendError(c, err)
+	var problem Problem
+	if decodeErr := json.Unmarshal(recorder.Body.Bytes(), &problem); decodeErr != nil {
+		t.Fatalf("decoding problem %q: %v", recorder.Body.String(), decodeErr)
+	}
+	return recorder, problem
+}
+
+func TestSendErrorCustomError(t *testing.T) {
+	recorder, problem := sendError(t, ErrInvalidValueInLimit)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, ProblemContentType) {
+		t.Errorf("content type = %q, want %q", got, ProblemContentType)
+	}
+	want := Problem{
+		Type:      ProblemTypePrefix + "invalid_limit",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    "invalid value in limit",
+		Instance:  "/videos",
+		Code:      "invalid_limit",
+		RequestID: "request-1",
+		Errors:    []FieldError{{Field: "limit", Code: "invalid_limit", Message: "invalid value in limit"}},
+	}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("problem = %+v, want %+v", problem, want)
+	}
+}
+
+func TestSendErrorMapsErrors(t *testing.T) {
+	var typeErr error
+	var request struct {
+		Limit int `json:"limit"`
+	}
+	typeErr = json.Unmarshal([]byte(`{"limit":"ten"}`), &request)
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		field  string
+	}{
+		{"wrapped custom error", fmt.Errorf("saving: %w", ErrWebhookNotFound), http.StatusNotFound, "webhook_not_found", ""},
+		{"syntax error", json.Unmarshal([]byte(`{`), &request), http.StatusBadRequest, "invalid_request_body", ""},
+		{"type error", typeErr, http.StatusBadRequest, "invalid_type", "limit"},
+		{"no rows", fmt.Errorf("getting video: %w", pgx.ErrNoRows), http.StatusNotFound, "not_found", ""},
+		{"unique violation", &pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "videos_pkey"`}, http.StatusConflict, "conflict", ""},
+		{"data exception", &pgconn.PgError{Code: "22P02", Message: "invalid input syntax"}, http.StatusBadRequest, "invalid_value", ""},
+		{"query canceled", &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}, http.StatusServiceUnavailable, "timeout", ""},
+		{"other database error", &pgconn.PgError{Code: "42P01", Message: `relation "videos" does not exist`}, http.StatusInternalServerError, "internal_error", ""},
+		{"internal error", errors.New("dial tcp 10.0.0.1:5432: connection refused"), http.StatusInternalServerError, "internal_error", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder, problem := sendError(t, test.err)
+			if recorder.Code != test.status || problem.Status != test.status {
+				t.Errorf("status = %d and %d, want %d", recorder.Code, problem.Status, test.status)
+			}
+			if problem.Code != test.code {
+				t.Errorf("code = %q, want %q", problem.Code, test.code)
+			}
+			if test.field != "" && (len(problem.Errors) != 1 || problem.Errors[0].Field != test.field) {
+				t.Errorf("errors = %+v, want an error of field %q", problem.Errors, test.field)
+			}
+			var customErr *Error
+			if !errors.As(test.err, &customErr) && strings.Contains(recorder.Body.String(), test.err.Error()) {
+				t.Errorf("response %q exposes the error %q", recorder.Body.String(), test.err)
+			}
+		})
+	}
+}
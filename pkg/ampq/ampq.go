@@ -2,8 +2,15 @@ package ampq
 
 import (
 	"context"
+	"github.com/Gohelraj/youtube-search-api/pkg/metrics"
+	"github.com/Gohelraj/youtube-search-api/pkg/tracing"
 	amqp "github.com/rabbitmq/amqp091-go"
-	"log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,7 +21,9 @@ type queue struct {
 	errorChannel chan *amqp.Error
 	connection   *amqp.Connection
 	channel      *amqp.Channel
-	closed       bool
+	closed       atomic.Bool
+	// connected is read by the readiness checks while the connection is reopened
+	connected atomic.Bool
 }
 
 // NewQueue make connection and creates queue.
@@ -29,32 +38,79 @@ func NewQueue(url string, qName string) *queue {
 	return q
 }
 
-// Send sends message to queue.
-func (q *queue) Send(message []byte) {
+// Send sends message to queue. The message is timestamped so that consumers can measure the lag of the queue, and
+// carries the trace context of the publish span in its headers so that consuming it continues the trace.
+func (q *queue) Send(ctx context.Context, message []byte) {
+	ctx, span := tracing.Tracer().Start(ctx, q.name+" publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystemKey.String("rabbitmq"), semconv.MessagingDestinationKey.String(q.name)))
+	defer span.End()
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
 	err := q.channel.PublishWithContext(
-		context.Background(),
+		ctx,
 		"",     // exchange
 		q.name, // routing key
 		false,  // mandatory
 		false,  // immediate
 		amqp.Publishing{
 			ContentType: "text/plain",
+			Headers:     headers,
+			Timestamp:   time.Now(),
 			Body:        message,
 		})
 	if err != nil {
-		log.Println("Sending message to queue failed: ", err)
+		metrics.QueueMessagesPublished.WithLabelValues(q.name, "error").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Sending message to queue failed", "queue", q.name, "err", err)
+		return
 	}
+	metrics.QueueMessagesPublished.WithLabelValues(q.name, "success").Inc()
+}
+
+// IsConnected reports whether the connection to RabbitMQ is open
+func (q *queue) IsConnected() bool {
+	return q.connected.Load()
+}
+
+// StartConsumeSpan starts the span of processing a message consumed from the queue, continuing the trace of the
+// message's publish span. The span must be ended once the message is acked or nacked.
+func StartConsumeSpan(queueName string, delivery amqp.Delivery) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(delivery.Headers))
+	return tracing.Tracer().Start(ctx, queueName+" process", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(semconv.MessagingSystemKey.String("rabbitmq"), semconv.MessagingDestinationKey.String(queueName),
+			semconv.MessagingOperationProcess))
+}
+
+// headerCarrier carries the trace context in the headers of a message
+type headerCarrier amqp.Table
+
+func (h headerCarrier) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 // Consumer returns channel for consuming messages from queue.
 func (q *queue) Consumer() (<-chan amqp.Delivery, error) {
-	log.Println("Registering consumer...")
+	slog.Info("Registering consumer...", "queue", q.name)
 	deliveries, err := q.registerQueueConsumer()
 	if err != nil {
-		log.Println("Consumer registration failed: ", err)
+		slog.Error("Consumer registration failed", "queue", q.name, "err", err)
 		return deliveries, err
 	}
-	log.Println("Consumer registered!")
+	slog.Info("Consumer registered!", "queue", q.name)
 	return deliveries, nil
 }
 
@@ -67,11 +123,12 @@ func (q *queue) connect() {
 			q.connection.NotifyClose(q.errorChannel)
 			q.openChannel()
 			q.declareQueue()
+			q.connected.Store(true)
 			return
 		}
 
 		if err != nil {
-			log.Println("Connection to rabbitmq failed. Retrying in 1 sec... ", err)
+			slog.Error("Connection to rabbitmq failed. Retrying in 5 sec...", "err", err)
 		}
 		time.Sleep(5000 * time.Millisecond)
 	}
@@ -80,13 +137,22 @@ func (q *queue) connect() {
 func (q *queue) reconnector() {
 	for {
 		err := <-q.errorChannel
-		if !q.closed {
-			log.Println("Reconnecting after connection closed: ", err)
-			q.connect()
+		q.connected.Store(false)
+		if q.closed.Load() {
+			return
 		}
+		slog.Warn("Reconnecting after connection closed", "err", err)
+		q.connect()
 	}
 }
 
+// Close closes the connection without reconnecting, the messages which are not acknowledged yet are redelivered to
+// the other consumers
+func (q *queue) Close() error {
+	q.closed.Store(true)
+	return q.connection.Close()
+}
+
 func (q *queue) declareQueue() {
 	_, err := q.channel.QueueDeclare(
 		q.name, // name
@@ -97,14 +163,14 @@ func (q *queue) declareQueue() {
 		nil,    // arguments
 	)
 	if err != nil {
-		log.Println("Queue declaration failed: ", err)
+		slog.Error("Queue declaration failed", "queue", q.name, "err", err)
 	}
 }
 
 func (q *queue) openChannel() {
 	channel, err := q.connection.Channel()
 	if err != nil {
-		log.Println("Opening channel failed: ", err)
+		slog.Error("Opening channel failed", "err", err)
 	}
 	q.channel = channel
 }
@@ -120,7 +186,7 @@ func (q *queue) registerQueueConsumer() (<-chan amqp.Delivery, error) {
 		nil,    // args
 	)
 	if err != nil {
-		log.Println("Consuming messages from queue failed: ", err)
+		slog.Error("Consuming messages from queue failed", "queue", q.name, "err", err)
 	}
 	return msgs, err
 }
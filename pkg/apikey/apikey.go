@@ -0,0 +1,36 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// keyPrefix starts all the API keys, so that leaked keys are easy to recognize
+const keyPrefix = "ysa_"
+
+// displayedPrefixLength is the length of the start of a key which identifies it to the admins
+const displayedPrefixLength = len(keyPrefix) + 8
+
+// Generate returns a new random API key along with its displayed prefix and its hash to store
+func Generate() (key string, prefix string, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:displayedPrefixLength], Hash(key), nil
+}
+
+// Hash returns the hex encoded SHA-256 hash of the key. The keys are random, so a fast hash is enough to not store them in plain text.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsWellFormed returns true if the key could have been generated, so that malformed keys are rejected without a lookup
+func IsWellFormed(key string) bool {
+	return strings.HasPrefix(key, keyPrefix) && len(key) == len(keyPrefix)+base64.RawURLEncoding.EncodedLen(32)
+}
@@ -0,0 +1,44 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !IsWellFormed(key) {
+		t.Errorf("generated key %q is not well formed", key)
+	}
+	if !strings.HasPrefix(key, prefix) || len(prefix) != 12 {
+		t.Errorf("prefix = %q of key %q", prefix, key)
+	}
+	if hash != Hash(key) || len(hash) != 64 {
+		t.Errorf("hash = %q, want the SHA-256 hash of the key", hash)
+	}
+	otherKey, _, _, _ := Generate()
+	if otherKey == key {
+		t.Errorf("Generate() returned the same key twice")
+	}
+}
+
+func TestHash(t *testing.T) {
+	// SHA-256 of "abc" from FIPS 180-2
+	if got, want := Hash("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("Hash() = %q, want %q", got, want)
+	}
+}
+
+func TestIsWellFormed(t *testing.T) {
+	for _, key := range []string{"", "ysa_", "ysa_short", "abc_" + strings.Repeat("a", 43), "ysa_" + strings.Repeat("a", 44)} {
+		if IsWellFormed(key) {
+			t.Errorf("IsWellFormed(%q) = true", key)
+		}
+	}
+	if !IsWellFormed("ysa_" + strings.Repeat("a", 43)) {
+		t.Errorf("IsWellFormed() of a 43 characters secret = false")
+	}
+}
@@ -1,10 +1,16 @@
 package cron_job
 
 import (
+	"context"
+	"errors"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/robfig/cron/v3"
+	"sync/atomic"
 )
 
+// scheduler is the started cron scheduler
+var scheduler atomic.Pointer[cron.Cron]
+
 type CronJob struct {
 	CronObj *cron.Cron
 	PgxPool *pgxpool.Pool
@@ -17,7 +23,8 @@ func NewCronJobObject(c *cron.Cron, pgxPool *pgxpool.Pool) CronJob {
 	}
 }
 
-// Init initializes the cron job(s)
+// Init initializes the cron job(s) and starts scheduling them in the background. The scheduler is stored before it
+// starts, so that Stop always stops it.
 func Init(pgxPool *pgxpool.Pool) {
 	c := cron.New(
 		cron.WithParser(
@@ -25,5 +32,36 @@ func Init(pgxPool *pgxpool.Pool) {
 				cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)))
 	cronObj := NewCronJobObject(c, pgxPool)
 	cronObj.FetchYoutubeVideosAndAddToQueue()
+	scheduler.Store(c)
 	c.Start()
 }
+
+// Stop stops scheduling the jobs, the returned context is done once the running jobs are finished
+func Stop() context.Context {
+	c := scheduler.Load()
+	if c == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+	return c.Stop()
+}
+
+// Status returns an error when the cron scheduler is not running its jobs
+func Status() error {
+	c := scheduler.Load()
+	if c == nil {
+		return errors.New("cron scheduler is not started")
+	}
+	entries := c.Entries()
+	if len(entries) == 0 {
+		return errors.New("cron scheduler has no jobs")
+	}
+	// the next run of the jobs is only scheduled while the scheduler is running
+	for _, entry := range entries {
+		if entry.Next.IsZero() {
+			return errors.New("cron scheduler is not running")
+		}
+	}
+	return nil
+}
@@ -1,18 +1,28 @@
 package cron_job
 
 import (
+	"context"
 	"github.com/Gohelraj/youtube-search-api/config"
+	"github.com/Gohelraj/youtube-search-api/pkg/tracing"
 	"github.com/Gohelraj/youtube-search-api/pkg/youtube"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"os"
 )
 
 // FetchYoutubeVideosAndAddToQueue fetches youtube videos and adds to queue
 func (c CronJob) FetchYoutubeVideosAndAddToQueue() {
 	_, err := c.CronObj.AddFunc(config.Conf.CronSpecsToFetchVideos, func() {
-		log.Println("Fetching youtube videos")
-		youtube.SearchVideosFromYoutubeAndAddToQueue(config.Conf.VideoKeyword, c.PgxPool)
+		// each run starts a trace followed through YouTube, the queue and the database
+		ctx, span := tracing.Tracer().Start(context.Background(), "FetchYoutubeVideos", trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attribute.String("youtube.keyword", config.Conf.VideoKeyword)))
+		defer span.End()
+		slog.InfoContext(ctx, "Fetching youtube videos", "keyword", config.Conf.VideoKeyword)
+		youtube.SearchVideosFromYoutubeAndAddToQueue(ctx, config.Conf.VideoKeyword, c.PgxPool)
 	})
 	if err != nil {
-		log.Fatalf("error adding cron job: %v\n", err)
+		slog.Error("error adding cron job", "err", err)
+		os.Exit(1)
 	}
 }
\ No newline at end of file
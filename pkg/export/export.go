@@ -0,0 +1,181 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Export formats of videos, parquet is only written to files as it can't be read until it is complete
+const (
+	FormatNDJSON  = "ndjson"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// ContentTypes are the media types of the export formats
+var ContentTypes = map[string]string{
+	FormatNDJSON:  "application/x-ndjson",
+	FormatCSV:     "text/csv; charset=utf-8",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+// Writer writes the exported videos, Close must be called to flush the videos once all of them are written.
+// Flush writes the buffered videos, except for parquet whose videos are only written along with their row group.
+type Writer interface {
+	Write(video model.VideoMetadata) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter returns a writer of the videos in the given format to w, which is not closed by the writer
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatNDJSON:
+		buffer := bufio.NewWriter(w)
+		return &ndjsonWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+	case FormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvVideoWriter{writer: csvWriter}, nil
+	case FormatParquet:
+		parquetWriter, err := writer.NewParquetWriterFromWriter(w, new(parquetVideo), 1)
+		if err != nil {
+			return nil, err
+		}
+		parquetWriter.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetVideoWriter{writer: parquetWriter}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+type ndjsonWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(video model.VideoMetadata) error {
+	// the encoder terminates each video with a newline
+	return n.encoder.Encode(video)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.buffer.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.Flush()
+}
+
+var csvHeader = []string{"id", "youtube_id", "title", "description", "published_at", "thumbnail_url", "language", "channel_id",
+	"channel_title", "duration_seconds", "keyword", "view_count", "cluster_id", "duplicates"}
+
+type csvVideoWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvVideoWriter) Write(video model.VideoMetadata) error {
+	return c.writer.Write([]string{
+		strconv.FormatInt(video.ID, 10), video.YoutubeID, video.Title, stringValue(video.Description), video.PublishedAt.UTC().Format(time.RFC3339),
+		stringValue(video.ThumbnailURL), stringValue(video.Language), stringValue(video.ChannelID), stringValue(video.ChannelTitle),
+		intValue(video.DurationSeconds), stringValue(video.Keyword), int64Value(video.ViewCount), int64Value(video.ClusterID), intValue(video.Duplicates),
+	})
+}
+
+func (c *csvVideoWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvVideoWriter) Close() error {
+	return c.Flush()
+}
+
+// parquetVideo is the parquet schema of the exported videos, published_at is stored as milliseconds since epoch
+type parquetVideo struct {
+	ID              int64   `parquet:"name=id, type=INT64"`
+	YoutubeID       string  `parquet:"name=youtube_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Title           string  `parquet:"name=title, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Description     *string `parquet:"name=description, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	PublishedAt     int64   `parquet:"name=published_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ThumbnailURL    *string `parquet:"name=thumbnail_url, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Language        *string `parquet:"name=language, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	ChannelID       *string `parquet:"name=channel_id, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	ChannelTitle    *string `parquet:"name=channel_title, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	DurationSeconds *int32  `parquet:"name=duration_seconds, type=INT32, repetitiontype=OPTIONAL"`
+	Keyword         *string `parquet:"name=keyword, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	ViewCount       *int64  `parquet:"name=view_count, type=INT64, repetitiontype=OPTIONAL"`
+	ClusterID       *int64  `parquet:"name=cluster_id, type=INT64, repetitiontype=OPTIONAL"`
+	Duplicates      *int32  `parquet:"name=duplicates, type=INT32, repetitiontype=OPTIONAL"`
+}
+
+type parquetVideoWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func (p *parquetVideoWriter) Write(video model.VideoMetadata) error {
+	return p.writer.Write(parquetVideo{
+		ID:              video.ID,
+		YoutubeID:       video.YoutubeID,
+		Title:           video.Title,
+		Description:     video.Description,
+		PublishedAt:     video.PublishedAt.UnixMilli(),
+		ThumbnailURL:    video.ThumbnailURL,
+		Language:        video.Language,
+		ChannelID:       video.ChannelID,
+		ChannelTitle:    video.ChannelTitle,
+		DurationSeconds: int32Pointer(video.DurationSeconds),
+		Keyword:         video.Keyword,
+		ViewCount:       video.ViewCount,
+		ClusterID:       video.ClusterID,
+		Duplicates:      int32Pointer(video.Duplicates),
+	})
+}
+
+func (p *parquetVideoWriter) Flush() error {
+	return nil
+}
+
+// Close writes the buffered row group and the footer of the parquet file
+func (p *parquetVideoWriter) Close() error {
+	return p.writer.WriteStop()
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func intValue(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func int64Value(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+func int32Pointer(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
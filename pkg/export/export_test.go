@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testVideos() []model.VideoMetadata {
+	description := "Kohli's six, the \"final\" over\nlive"
+	language := "en"
+	durationSeconds := 95
+	return []model.VideoMetadata{
+		{
+			ID:              1,
+			YoutubeID:       "abc123",
+			Title:           "IPL final highlights",
+			Description:     &description,
+			PublishedAt:     time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+			Language:        &language,
+			DurationSeconds: &durationSeconds,
+		},
+		{
+			ID:          2,
+			YoutubeID:   "def456",
+			Title:       "Net practice",
+			PublishedAt: time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func writeVideos(t *testing.T, format string) []byte {
+	var buffer bytes.Buffer
+	w, err := NewWriter(format, &buffer)
+	if err != nil {
+		t.Fatalf("NewWriter(%q) error = %v", format, err)
+	}
+	for _, video := range testVideos() {
+		if err = w.Write(video); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestNDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(string(writeVideos(t, FormatNDJSON)), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ndjson has %d lines, want 2", len(lines))
+	}
+	var video model.VideoMetadata
+	if err := json.Unmarshal([]byte(lines[0]), &video); err != nil {
+		t.Fatalf("ndjson line is not valid JSON: %v", err)
+	}
+	if video.YoutubeID != "abc123" || video.Description == nil || *video.Description != *testVideos()[0].Description {
+		t.Errorf("ndjson video = %+v", video)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeVideos(t, FormatCSV))).ReadAll()
+	if err != nil {
+		t.Fatalf("csv is not valid: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("csv has %d records, want the header and 2 videos", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("csv header = %v", records[0])
+	}
+	want := []string{"1", "abc123", "IPL final highlights", "Kohli's six, the \"final\" over\nlive", "2026-10-18T09:30:00Z", "", "en", "",
+		"", "95", "", "", "", ""}
+	if strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Errorf("csv record = %q, want %q", records[1], want)
+	}
+}
+
+func TestParquet(t *testing.T) {
+	body := writeVideos(t, FormatParquet)
+	// parquet files start and end with the magic number
+	if !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Errorf("parquet file doesn't start and end with PAR1")
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Errorf("NewWriter(%q) error = nil, want an error", "xml")
+	}
+}
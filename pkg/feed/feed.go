@@ -0,0 +1,212 @@
+package feed
+
+import (
+	"encoding/xml"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"time"
+)
+
+const (
+	atomNamespace  = "http://www.w3.org/2005/Atom"
+	mediaNamespace = "http://search.yahoo.com/mrss/"
+	// thumbnailType is the media type of YouTube thumbnails
+	thumbnailType = "image/jpeg"
+)
+
+// Feed of videos, SelfURL is the URL the feed is fetched from, which identifies it
+type Feed struct {
+	Title       string
+	Description string
+	SelfURL     string
+	Videos      []model.VideoMetadata
+}
+
+// Updated returns the time the feed was last updated, which is the latest time its videos were inserted, so that a
+// newly fetched video published earlier than the others still updates the feed. The publish time of a video is used
+// when its insertion time is unknown.
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, video := range f.Videos {
+		inserted := video.CreatedAt
+		if inserted.IsZero() {
+			inserted = video.PublishedAt
+		}
+		if inserted.After(updated) {
+			updated = inserted
+		}
+	}
+	return updated
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Media   string      `xml:"xmlns:media,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string          `xml:"id"`
+	Title     string          `xml:"title"`
+	Links     []atomLink      `xml:"link"`
+	Published string          `xml:"published"`
+	Updated   string          `xml:"updated"`
+	Author    *atomAuthor     `xml:"author"`
+	Summary   *atomSummary    `xml:"summary"`
+	Thumbnail *mediaThumbnail `xml:"media:thumbnail"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomSummary struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type mediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+// Atom returns the Atom 1.0 document of the feed, the thumbnails of the videos are linked as enclosures
+// and as Media RSS thumbnails.
+func Atom(f Feed) ([]byte, error) {
+	feed := atomFeed{
+		Xmlns:   atomNamespace,
+		Media:   mediaNamespace,
+		ID:      f.SelfURL,
+		Title:   f.Title,
+		Updated: f.Updated().UTC().Format(time.RFC3339),
+		Links:   []atomLink{{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL}},
+		Entries: make([]atomEntry, 0, len(f.Videos)),
+	}
+	for _, video := range f.Videos {
+		published := video.PublishedAt.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			ID:        "yt:video:" + video.YoutubeID,
+			Title:     video.Title,
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: videoURL(video)}},
+			Published: published,
+			// videos are not updated once they are stored
+			Updated: published,
+		}
+		if video.ChannelTitle != nil {
+			entry.Author = &atomAuthor{Name: *video.ChannelTitle}
+			if video.ChannelID != nil {
+				entry.Author.URI = channelURL(*video.ChannelID)
+			}
+		}
+		if video.Description != nil && *video.Description != "" {
+			entry.Summary = &atomSummary{Type: "text", Text: *video.Description}
+		}
+		if video.ThumbnailURL != nil && *video.ThumbnailURL != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Type: thumbnailType, Href: *video.ThumbnailURL})
+			entry.Thumbnail = &mediaThumbnail{URL: *video.ThumbnailURL}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshal(feed)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Media   string     `xml:"xmlns:media,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	Description string          `xml:"description,omitempty"`
+	GUID        rssGUID         `xml:"guid"`
+	PubDate     string          `xml:"pubDate"`
+	Enclosure   *rssEnclosure   `xml:"enclosure"`
+	Thumbnail   *mediaThumbnail `xml:"media:thumbnail"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// RSS returns the RSS 2.0 document of the feed, the thumbnails of the videos are enclosures and Media RSS thumbnails.
+func RSS(f Feed) ([]byte, error) {
+	document := rssDocument{
+		Version: "2.0",
+		Atom:    atomNamespace,
+		Media:   mediaNamespace,
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.SelfURL,
+			Description: f.Description,
+			AtomLink:    atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfURL},
+			Items:       make([]rssItem, 0, len(f.Videos)),
+		},
+	}
+	if updated := f.Updated(); !updated.IsZero() {
+		document.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, video := range f.Videos {
+		item := rssItem{
+			Title:   video.Title,
+			Link:    videoURL(video),
+			GUID:    rssGUID{Value: "yt:video:" + video.YoutubeID},
+			PubDate: video.PublishedAt.UTC().Format(time.RFC1123Z),
+		}
+		if video.Description != nil {
+			item.Description = *video.Description
+		}
+		if video.ThumbnailURL != nil && *video.ThumbnailURL != "" {
+			// the size of the thumbnails is not known, and 0 is the conventional length of unknown size
+			item.Enclosure = &rssEnclosure{URL: *video.ThumbnailURL, Length: 0, Type: thumbnailType}
+			item.Thumbnail = &mediaThumbnail{URL: *video.ThumbnailURL}
+		}
+		document.Channel.Items = append(document.Channel.Items, item)
+	}
+	return marshal(document)
+}
+
+func marshal(document interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func videoURL(video model.VideoMetadata) string {
+	return "https://www.youtube.com/watch?v=" + video.YoutubeID
+}
+
+func channelURL(channelID string) string {
+	return "https://www.youtube.com/channel/" + channelID
+}
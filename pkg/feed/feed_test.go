@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"encoding/xml"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	description := "Kohli's six & the final over <live>"
+	thumbnailURL := "https://i.ytimg.com/vi/abc123/mqdefault.jpg"
+	channelID := "UCxyz"
+	channelTitle := "Cricket Channel"
+	return Feed{
+		Title:       "YouTube videos",
+		Description: "Latest YouTube videos",
+		SelfURL:     "https://api.example.com/videos/feed.atom",
+		Videos: []model.VideoMetadata{
+			{
+				YoutubeID:    "abc123",
+				Title:        "IPL final highlights",
+				Description:  &description,
+				PublishedAt:  time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+				CreatedAt:    time.Date(2026, 10, 19, 8, 15, 0, 0, time.UTC),
+				ThumbnailURL: &thumbnailURL,
+				ChannelID:    &channelID,
+				ChannelTitle: &channelTitle,
+			},
+			{
+				YoutubeID:   "def456",
+				Title:       "Net practice",
+				PublishedAt: time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC),
+				CreatedAt:   time.Date(2026, 10, 19, 7, 5, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestAtom(t *testing.T) {
+	body, err := Atom(testFeed())
+	if err != nil {
+		t.Fatalf("Atom() error = %v", err)
+	}
+	var feed struct {
+		ID      string `xml:"http://www.w3.org/2005/Atom id"`
+		Updated string `xml:"http://www.w3.org/2005/Atom updated"`
+		Entries []struct {
+			ID    string `xml:"http://www.w3.org/2005/Atom id"`
+			Links []struct {
+				Rel  string `xml:"rel,attr"`
+				Type string `xml:"type,attr"`
+				Href string `xml:"href,attr"`
+			} `xml:"http://www.w3.org/2005/Atom link"`
+			Summary   string `xml:"http://www.w3.org/2005/Atom summary"`
+			Thumbnail struct {
+				URL string `xml:"url,attr"`
+			} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+		} `xml:"http://www.w3.org/2005/Atom entry"`
+	}
+	if err = xml.Unmarshal(body, &feed); err != nil {
+		t.Fatalf("Atom() is not valid XML: %v\n%s", err, body)
+	}
+	if feed.ID != "https://api.example.com/videos/feed.atom" {
+		t.Errorf("feed id = %q", feed.ID)
+	}
+	// the feed is updated when a video is inserted, even if it was published before the latest video
+	if feed.Updated != "2026-10-19T08:15:00Z" {
+		t.Errorf("feed updated = %q, want %q", feed.Updated, "2026-10-19T08:15:00Z")
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("feed has %d entries, want 2", len(feed.Entries))
+	}
+	entry := feed.Entries[0]
+	if entry.ID != "yt:video:abc123" {
+		t.Errorf("entry id = %q", entry.ID)
+	}
+	if entry.Summary != "Kohli's six & the final over <live>" {
+		t.Errorf("entry summary = %q", entry.Summary)
+	}
+	if entry.Thumbnail.URL != "https://i.ytimg.com/vi/abc123/mqdefault.jpg" {
+		t.Errorf("entry thumbnail = %q", entry.Thumbnail.URL)
+	}
+	var enclosure string
+	for _, link := range entry.Links {
+		if link.Rel == "enclosure" && link.Type == "image/jpeg" {
+			enclosure = link.Href
+		}
+	}
+	if enclosure != "https://i.ytimg.com/vi/abc123/mqdefault.jpg" {
+		t.Errorf("entry enclosure = %q", enclosure)
+	}
+	if len(feed.Entries[1].Links) != 1 {
+		t.Errorf("entry without thumbnail has %d links, want only the alternate link", len(feed.Entries[1].Links))
+	}
+}
+
+func TestRSS(t *testing.T) {
+	body, err := RSS(testFeed())
+	if err != nil {
+		t.Fatalf("RSS() error = %v", err)
+	}
+	if !strings.HasPrefix(string(body), xml.Header) {
+		t.Errorf("RSS() doesn't start with the XML header")
+	}
+	var document struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Link      string `xml:"link"`
+				GUID      string `xml:"guid"`
+				PubDate   string `xml:"pubDate"`
+				Enclosure *struct {
+					URL    string `xml:"url,attr"`
+					Length string `xml:"length,attr"`
+					Type   string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err = xml.Unmarshal(body, &document); err != nil {
+		t.Fatalf("RSS() is not valid XML: %v\n%s", err, body)
+	}
+	if document.Version != "2.0" {
+		t.Errorf("rss version = %q", document.Version)
+	}
+	if document.Channel.LastBuildDate != "Mon, 19 Oct 2026 08:15:00 +0000" {
+		t.Errorf("channel lastBuildDate = %q", document.Channel.LastBuildDate)
+	}
+	if len(document.Channel.Items) != 2 {
+		t.Fatalf("channel has %d items, want 2", len(document.Channel.Items))
+	}
+	item := document.Channel.Items[0]
+	if item.Link != "https://www.youtube.com/watch?v=abc123" || item.GUID != "yt:video:abc123" {
+		t.Errorf("item link = %q, guid = %q", item.Link, item.GUID)
+	}
+	if item.Enclosure == nil || item.Enclosure.URL != "https://i.ytimg.com/vi/abc123/mqdefault.jpg" || item.Enclosure.Type != "image/jpeg" || item.Enclosure.Length != "0" {
+		t.Errorf("item enclosure = %+v", item.Enclosure)
+	}
+	if document.Channel.Items[1].Enclosure != nil {
+		t.Errorf("item without thumbnail has an enclosure")
+	}
+}
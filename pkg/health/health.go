@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errTimeout is the error of the checks which don't return within the timeout
+var errTimeout = errors.New("check timed out")
+
+// Statuses of the components and of the report
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns an error when its component is not healthy. The error is shown in the report, so it must not expose
+// internal details such as the hosts of the dependencies.
+type Check func(ctx context.Context) error
+
+// ComponentStatus is the status of a component with the error of its check when it failed
+type ComponentStatus struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the status of all the components, which is ok only if all of them are ok
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// Healthy reports whether all the components are healthy
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Run runs the checks concurrently, each with the given timeout, and reports the status of their components.
+// A check which doesn't return within the timeout fails.
+func Run(ctx context.Context, checks map[string]Check, timeout time.Duration) Report {
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(checks))}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			status := run(ctx, check, timeout)
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Components[name] = status
+			if status.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if err == context.DeadlineExceeded {
+			err = errTimeout
+		}
+	}
+	status := ComponentStatus{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Status = StatusFail
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), map[string]Check{
+		"postgres": func(context.Context) error { return nil },
+		"cron":     func(context.Context) error { return nil },
+	}, time.Second)
+	if !report.Healthy() || len(report.Components) != 2 || report.Components["postgres"].Status != StatusOK {
+		t.Errorf("Run() of passing checks = %+v, want ok", report)
+	}
+
+	report = Run(context.Background(), map[string]Check{
+		"postgres": func(context.Context) error { return nil },
+		"amqp":     func(context.Context) error { return errors.New("not connected to RabbitMQ") },
+		// a check ignoring its context still fails at the timeout
+		"cron": func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+	}, 10*time.Millisecond)
+	if report.Healthy() || report.Status != StatusFail {
+		t.Errorf("Run() status = %s, want %s", report.Status, StatusFail)
+	}
+	want := map[string]ComponentStatus{
+		"postgres": {Status: StatusOK},
+		"amqp":     {Status: StatusFail, Error: "not connected to RabbitMQ"},
+		"cron":     {Status: StatusFail, Error: "check timed out"},
+	}
+	for name, status := range want {
+		got := report.Components[name]
+		if got.Status != status.Status || got.Error != status.Error {
+			t.Errorf("status of %s = %+v, want %+v", name, got, status)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Import formats of videos, which are the same as the export formats except for parquet
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// maxLineSize is the maximum size of an NDJSON line
+const maxLineSize = 1 << 20
+
+// Row of an imported file, Err is set instead of the video when the row can't be parsed.
+// Line is the line number of the row in the file, starting from 1.
+type Row struct {
+	Line  int
+	Video model.VideoMetadata
+	Err   error
+}
+
+// Reader reads the rows of the imported videos, Read returns io.EOF after the last row.
+// Errors other than io.EOF can't be recovered from, the errors of a single row are returned in the row instead.
+type Reader interface {
+	Read() (Row, error)
+}
+
+// NewReader returns a reader of the videos of the given format from r. CSV files must start with a header row
+// of the columns of the exported CSV files, which are matched by name and can be in any order.
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonReader{reader: bufio.NewReaderSize(r, 64*1024)}, nil
+	case FormatCSV:
+		return newCSVReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+type ndjsonReader struct {
+	reader *bufio.Reader
+	buffer []byte
+	line   int
+}
+
+func (n *ndjsonReader) Read() (Row, error) {
+	for {
+		line, tooLong, err := n.readLine()
+		if err != nil && err != io.EOF {
+			return Row{}, err
+		}
+		if err == io.EOF && len(line) == 0 && !tooLong {
+			return Row{}, io.EOF
+		}
+		n.line++
+		if tooLong {
+			return Row{Line: n.line, Err: fmt.Errorf("line is longer than %d bytes", maxLineSize)}, nil
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		row := Row{Line: n.line}
+		row.Err = json.Unmarshal(line, &row.Video)
+		return row, nil
+	}
+}
+
+// readLine reads the next line, io.EOF is returned with the last line when it doesn't end with a newline. A line longer
+// than maxLineSize is skipped rather than buffered, and tooLong is returned true for it, so that it is reported as
+// a row error and the rows after it are still read.
+func (n *ndjsonReader) readLine() (line []byte, tooLong bool, err error) {
+	n.buffer = n.buffer[:0]
+	for {
+		chunk, err := n.reader.ReadSlice('\n')
+		// the newline doesn't count towards the size of the line
+		if !tooLong && len(n.buffer)+len(bytes.TrimSuffix(chunk, []byte("\n"))) > maxLineSize {
+			tooLong = true
+			n.buffer = n.buffer[:0]
+		}
+		if !tooLong {
+			n.buffer = append(n.buffer, chunk...)
+		}
+		if err != bufio.ErrBufferFull {
+			return n.buffer, tooLong, err
+		}
+	}
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	// rows with missing or extra columns are reported as row errors
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV header row is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(header))
+	hasYoutubeID := false
+	for i, column := range header {
+		// spreadsheets can save CSV files with a byte order mark
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		hasYoutubeID = hasYoutubeID || columns[i] == "youtube_id"
+	}
+	if !hasYoutubeID {
+		return nil, errors.New("CSV header must have the youtube_id column")
+	}
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (Row, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return Row{}, io.EOF
+	}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		return Row{Line: parseError.StartLine, Err: parseError.Err}, nil
+	}
+	if err != nil {
+		return Row{}, err
+	}
+	line, _ := c.reader.FieldPos(0)
+	row := Row{Line: line}
+	if len(record) != len(c.columns) {
+		row.Err = fmt.Errorf("row has %d columns, want %d", len(record), len(c.columns))
+		return row, nil
+	}
+	for i, value := range record {
+		if err = setColumn(&row.Video, c.columns[i], value); err != nil {
+			row.Err = fmt.Errorf("invalid %s: %w", c.columns[i], err)
+			return row, nil
+		}
+	}
+	return row, nil
+}
+
+// setColumn sets the field of the video of the given CSV column, empty values are left unset
+func setColumn(video *model.VideoMetadata, column string, value string) error {
+	if value == "" {
+		return nil
+	}
+	var err error
+	switch column {
+	case "youtube_id":
+		video.YoutubeID = value
+	case "title":
+		video.Title = value
+	case "description":
+		video.Description = &value
+	case "published_at":
+		video.PublishedAt, err = parsePublishedAt(value)
+	case "thumbnail_url":
+		video.ThumbnailURL = &value
+	case "language":
+		video.Language = &value
+	case "channel_id":
+		video.ChannelID = &value
+	case "channel_title":
+		video.ChannelTitle = &value
+	case "duration_seconds":
+		var durationSeconds int
+		durationSeconds, err = strconv.Atoi(value)
+		video.DurationSeconds = &durationSeconds
+	case "keyword":
+		video.Keyword = &value
+	case "view_count":
+		var viewCount int64
+		viewCount, err = strconv.ParseInt(value, 10, 64)
+		video.ViewCount = &viewCount
+	}
+	// the other columns of the exported files, like id and cluster_id, are assigned on insert
+	return err
+}
+
+// parsePublishedAt parses an RFC 3339 date time, or a date as spreadsheets usually have
+func parsePublishedAt(value string) (time.Time, error) {
+	publishedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if date, dateErr := time.Parse("2006-01-02", value); dateErr == nil {
+			return date, nil
+		}
+		return time.Time{}, errors.New("must be an RFC 3339 date time or a YYYY-MM-DD date")
+	}
+	return publishedAt, nil
+}
+
+// IsIDOnly returns true if the video has no title, so its metadata must be fetched from YouTube
+func IsIDOnly(video model.VideoMetadata) bool {
+	return strings.TrimSpace(video.Title) == ""
+}
+
+// columnLimits are the maximum number of characters of the text columns of the videos table
+var columnLimits = []struct {
+	column string
+	value  func(video *model.VideoMetadata) *string
+	limit  int
+}{
+	{"youtube_id", func(video *model.VideoMetadata) *string { return &video.YoutubeID }, 20},
+	{"title", func(video *model.VideoMetadata) *string { return &video.Title }, 200},
+	{"description", func(video *model.VideoMetadata) *string { return video.Description }, 5000},
+	{"thumbnail_url", func(video *model.VideoMetadata) *string { return video.ThumbnailURL }, 500},
+	{"language", func(video *model.VideoMetadata) *string { return video.Language }, 20},
+	{"channel_id", func(video *model.VideoMetadata) *string { return video.ChannelID }, 50},
+	{"channel_title", func(video *model.VideoMetadata) *string { return video.ChannelTitle }, 200},
+	{"keyword", func(video *model.VideoMetadata) *string { return video.Keyword }, 100},
+}
+
+// Normalize trims the text of the video and resets the fields assigned on insert, and validates the video against
+// the limits of the videos table. The thumbnail of the video defaults to the medium thumbnail of YouTube.
+func Normalize(video *model.VideoMetadata) error {
+	video.ID = 0
+	video.ClusterID = nil
+	video.Duplicates = nil
+	video.Fingerprint = nil
+	video.DuplicateOf = nil
+	video.Highlight = nil
+	video.Score = nil
+	video.YoutubeID = strings.TrimSpace(video.YoutubeID)
+	video.Title = strings.TrimSpace(video.Title)
+	if video.YoutubeID == "" {
+		return errors.New("youtube_id is required")
+	}
+	if video.Title == "" {
+		return errors.New("title is required")
+	}
+	if video.PublishedAt.IsZero() {
+		return errors.New("published_at is required")
+	}
+	if video.ThumbnailURL == nil || *video.ThumbnailURL == "" {
+		thumbnailURL := "https://i.ytimg.com/vi/" + video.YoutubeID + "/mqdefault.jpg"
+		video.ThumbnailURL = &thumbnailURL
+	}
+	for _, column := range columnLimits {
+		if value := column.value(video); value != nil && utf8.RuneCountInString(*value) > column.limit {
+			return fmt.Errorf("%s must be at most %d characters", column.column, column.limit)
+		}
+	}
+	if video.DurationSeconds != nil && *video.DurationSeconds < 0 {
+		return errors.New("duration_seconds must not be negative")
+	}
+	if video.ViewCount != nil && *video.ViewCount < 0 {
+		return errors.New("view_count must not be negative")
+	}
+	// published_at is stored in UTC without time zone
+	video.PublishedAt = video.PublishedAt.UTC()
+	return nil
+}
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, format string, input string) []Row {
+	reader, err := NewReader(format, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader(%q) error = %v", format, err)
+	}
+	var rows []Row
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	rows := readAll(t, FormatNDJSON, `{"id":7,"youtubeId":"abc123","title":"IPL final","publishedAt":"2026-10-18T09:30:00Z","clusterId":7}
+
+{"youtubeId":"def456"}
+not json
+`)
+	if len(rows) != 3 {
+		t.Fatalf("read %d rows, want 3", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].Line != 1 || rows[0].Video.YoutubeID != "abc123" || rows[0].Video.Title != "IPL final" {
+		t.Errorf("rows[0] = %+v", rows[0])
+	}
+	// blank lines are skipped but counted
+	if rows[1].Err != nil || rows[1].Line != 3 || !IsIDOnly(rows[1].Video) {
+		t.Errorf("rows[1] = %+v", rows[1])
+	}
+	if rows[2].Err == nil || rows[2].Line != 4 {
+		t.Errorf("rows[2] = %+v, want an error on line 4", rows[2])
+	}
+}
+
+func TestNDJSONLineTooLong(t *testing.T) {
+	rows := readAll(t, FormatNDJSON, `{"youtubeId":"abc123","title":"`+strings.Repeat("a", maxLineSize)+`"}
+{"youtubeId":"def456"}`)
+	if len(rows) != 2 {
+		t.Fatalf("read %d rows, want 2", len(rows))
+	}
+	// the long line is a row error, and the rows after it are still read
+	if rows[0].Err == nil || rows[0].Line != 1 {
+		t.Errorf("rows[0] = %+v, want an error on line 1", rows[0])
+	}
+	if rows[1].Err != nil || rows[1].Line != 2 || rows[1].Video.YoutubeID != "def456" {
+		t.Errorf("rows[1] = %+v", rows[1])
+	}
+}
+
+func TestCSV(t *testing.T) {
+	rows := readAll(t, FormatCSV, "\ufeffYoutube_ID,title,published_at,duration_seconds,unknown\n"+
+		"abc123,\"IPL final, highlights\",2026-10-18,95,x\n"+
+		"def456,,,,\n"+
+		"ghi789,Net practice,yesterday,,\n"+
+		"jkl012,too few\n")
+	if len(rows) != 4 {
+		t.Fatalf("read %d rows, want 4", len(rows))
+	}
+	video := rows[0].Video
+	if rows[0].Err != nil || rows[0].Line != 2 || video.YoutubeID != "abc123" || video.Title != "IPL final, highlights" ||
+		!video.PublishedAt.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) || video.DurationSeconds == nil || *video.DurationSeconds != 95 {
+		t.Errorf("rows[0] = %+v", rows[0])
+	}
+	if rows[1].Err != nil || !IsIDOnly(rows[1].Video) || rows[1].Video.Description != nil {
+		t.Errorf("rows[1] = %+v", rows[1])
+	}
+	if rows[2].Err == nil || !strings.Contains(rows[2].Err.Error(), "published_at") {
+		t.Errorf("rows[2] error = %v, want an invalid published_at error", rows[2].Err)
+	}
+	if rows[3].Err == nil || rows[3].Line != 5 {
+		t.Errorf("rows[3] = %+v, want a column count error on line 5", rows[3])
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	for _, input := range []string{"", "title,published_at\nIPL final,2026-10-18\n"} {
+		if _, err := NewReader(FormatCSV, strings.NewReader(input)); err == nil {
+			t.Errorf("NewReader(%q) error = nil, want an error", input)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	publishedAt := time.Date(2026, 10, 18, 15, 0, 0, 0, time.FixedZone("IST", 19800))
+	video := model.VideoMetadata{ID: 7, YoutubeID: " abc123 ", Title: " IPL final ", PublishedAt: publishedAt}
+	if err := Normalize(&video); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if video.ID != 0 || video.YoutubeID != "abc123" || video.Title != "IPL final" || video.PublishedAt.Location() != time.UTC {
+		t.Errorf("normalized video = %+v", video)
+	}
+	if video.ThumbnailURL == nil || *video.ThumbnailURL != "https://i.ytimg.com/vi/abc123/mqdefault.jpg" {
+		t.Errorf("normalized thumbnail = %v", video.ThumbnailURL)
+	}
+
+	longDescription := strings.Repeat("é", 5001)
+	negativeViews := int64(-1)
+	tests := []struct {
+		name  string
+		video model.VideoMetadata
+		want  string
+	}{
+		{"missing id", model.VideoMetadata{Title: "IPL", PublishedAt: publishedAt}, "youtube_id is required"},
+		{"missing title", model.VideoMetadata{YoutubeID: "abc123", PublishedAt: publishedAt}, "title is required"},
+		{"missing publish time", model.VideoMetadata{YoutubeID: "abc123", Title: "IPL"}, "published_at is required"},
+		{"long title", model.VideoMetadata{YoutubeID: "abc123", Title: strings.Repeat("a", 201), PublishedAt: publishedAt}, "title must be at most 200 characters"},
+		{"long description", model.VideoMetadata{YoutubeID: "abc123", Title: "IPL", Description: &longDescription, PublishedAt: publishedAt}, "description must be at most 5000 characters"},
+		{"negative views", model.VideoMetadata{YoutubeID: "abc123", Title: "IPL", ViewCount: &negativeViews, PublishedAt: publishedAt}, "view_count must not be negative"},
+	}
+	for _, tt := range tests {
+		if err := Normalize(&tt.video); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: Normalize() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	// the limits are in characters rather than bytes, like the varchar columns
+	description := strings.Repeat("é", 5000)
+	video = model.VideoMetadata{YoutubeID: "abc123", Title: "IPL", Description: &description, PublishedAt: publishedAt}
+	if err := Normalize(&video); err != nil {
+		t.Errorf("Normalize() of 5000 characters description error = %v", err)
+	}
+}
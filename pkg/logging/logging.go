@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/config"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Formats of the logs
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the context carrying the id of the request, which is added to the logs written
+// with the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the id of the request carried by the context, or an empty string
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Init sets the default logger writing the configured format at the configured level to stderr.
+// The standard library logger writes through the default logger too.
+func Init() error {
+	handler, err := NewHandler(os.Stderr, config.Conf.Log.Format, config.Conf.Log.Level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// NewHandler creates a handler writing the logs in the given format from the given level, which adds the request id
+// and the trace and span ids carried by the context of the logs to them
+func NewHandler(w io.Writer, format string, level string) (slog.Handler, error) {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+	options := &slog.HandlerOptions{Level: logLevel}
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return contextHandler{slog.NewJSONHandler(w, options)}, nil
+	case FormatText:
+		return contextHandler{slog.NewTextHandler(w, options)}, nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// contextHandler adds the request id and the trace and span ids carried by the context of the logs to them
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
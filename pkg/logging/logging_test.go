@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var out bytes.Buffer
+	handler, err := NewHandler(&out, FormatJSON, "info")
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	logger := slog.New(handler).With("keyword", "cricket")
+	logger.DebugContext(context.Background(), "dropped")
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "request", "status", 200)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("wrote %d lines, want the info log only: %s", len(lines), out.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line %q is not JSON: %v", lines[0], err)
+	}
+	if entry["msg"] != "request" || entry["request_id"] != "req-1" || entry["keyword"] != "cricket" || entry["status"] != float64(200) {
+		t.Errorf("log entry = %v", entry)
+	}
+}
+
+func TestNewHandlerInvalid(t *testing.T) {
+	if _, err := NewHandler(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Errorf("NewHandler() with xml format error = nil, want an error")
+	}
+	if _, err := NewHandler(&bytes.Buffer{}, FormatText, "verbose"); err == nil {
+		t.Errorf("NewHandler() with verbose level error = nil, want an error")
+	}
+}
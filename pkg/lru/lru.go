@@ -0,0 +1,71 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a fixed size, concurrency safe, least recently used cache whose entries expire after a TTL.
+type Cache[V any] struct {
+	size  int
+	ttl   time.Duration
+	mutex sync.Mutex
+	list  *list.List
+	items map[string]*list.Element
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// New creates a cache holding at most size entries, each valid for the given ttl.
+func New[V any](size int, ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		size:  size,
+		ttl:   ttl,
+		list:  list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored for the key, if it is present and not expired.
+func (c *Cache[V]) Get(key string) (value V, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	e := element.Value.(*entry[V])
+	if time.Now().After(e.expiresAt) {
+		c.list.Remove(element)
+		delete(c.items, key)
+		return value, false
+	}
+	c.list.MoveToFront(element)
+	return e.value, true
+}
+
+// Add stores the value for the key, evicting the least recently used entry when the cache is full.
+func (c *Cache[V]) Add(key string, value V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if element, ok := c.items[key]; ok {
+		e := element.Value.(*entry[V])
+		e.value, e.expiresAt = value, time.Now().Add(c.ttl)
+		c.list.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.list.PushFront(&entry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)})
+	if c.list.Len() > c.size {
+		oldest := c.list.Back()
+		c.list.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[V]).key)
+	}
+}
@@ -0,0 +1,31 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	cache := New[int](2, time.Minute)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	// "a" becomes the most recently used, so "b" gets evicted
+	if got, ok := cache.Get("a"); !ok || got != 1 {
+		t.Errorf("Get(a) = %v, %v, want 1, true", got, ok)
+	}
+	cache.Add("c", 3)
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Get(b) should be evicted")
+	}
+	if got, ok := cache.Get("c"); !ok || got != 3 {
+		t.Errorf("Get(c) = %v, %v, want 3, true", got, ok)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache := New[string](2, -time.Second)
+	cache.Add("a", "expired")
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("Get(a) should be expired")
+	}
+}
@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes the names of all the metrics of the API
+const namespace = "youtube_search_api"
+
+// Quota units of the YouTube Data API calls, see https://developers.google.com/youtube/v3/determine_quota_cost
+const (
+	SearchListQuotaUnits = 100
+	VideosListQuotaUnits = 1
+)
+
+var (
+	// HTTPRequestDuration is the latency of the HTTP requests by method, route and status code.
+	// The route is the matched route pattern rather than the path, so that path params don't explode the label values.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// GRPCRequestDuration is the latency of the gRPC calls by method and status code, the streams are recorded once
+	// they end
+	GRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latency of the gRPC calls by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// YoutubeAPICalls is the number of YouTube Data API calls by API key index and endpoint
+	YoutubeAPICalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "youtube_api_calls_total",
+		Help:      "Number of YouTube Data API calls by API key index and endpoint.",
+	}, []string{"key", "endpoint"})
+
+	// YoutubeAPIErrors is the number of failed YouTube Data API calls by API key index, endpoint and HTTP status code,
+	// the code is 0 when the API didn't respond
+	YoutubeAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "youtube_api_errors_total",
+		Help:      "Number of failed YouTube Data API calls by API key index, endpoint and HTTP status code.",
+	}, []string{"key", "endpoint", "code"})
+
+	// YoutubeAPIQuotaUnits is the number of quota units spent by API key index and endpoint
+	YoutubeAPIQuotaUnits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "youtube_api_quota_units_total",
+		Help:      "Number of YouTube Data API quota units spent by API key index and endpoint.",
+	}, []string{"key", "endpoint"})
+
+	// QueueMessagesPublished is the number of messages published to the queue, by whether publishing succeeded
+	QueueMessagesPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_published_total",
+		Help:      "Number of messages published to the queue by result (success or error).",
+	}, []string{"queue", "result"})
+
+	// QueueMessagesConsumed is the number of messages consumed from the queue
+	QueueMessagesConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_consumed_total",
+		Help:      "Number of messages consumed from the queue.",
+	}, []string{"queue"})
+
+	// QueueMessagesAcked is the number of consumed messages acknowledged as processed
+	QueueMessagesAcked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_acked_total",
+		Help:      "Number of consumed messages acknowledged as processed.",
+	}, []string{"queue"})
+
+	// QueueMessagesNacked is the number of consumed messages requeued to be processed again
+	QueueMessagesNacked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_nacked_total",
+		Help:      "Number of consumed messages negatively acknowledged to be processed again.",
+	}, []string{"queue"})
+
+	// QueueLag is the time between publishing a message and consuming it
+	QueueLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "queue_lag_seconds",
+		Help:      "Time between publishing a message to the queue and consuming it.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900},
+	}, []string{"queue"})
+
+	// InsertVideosDuration is the latency of inserting a batch of videos
+	InsertVideosDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "insert_videos_batch_duration_seconds",
+		Help:      "Latency of inserting a batch of videos.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// VideosInserted is the number of videos inserted by the batches
+	VideosInserted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "videos_inserted_total",
+		Help:      "Number of videos inserted by the batches.",
+	})
+
+	// VideosSkipped is the number of videos of the batches skipped as they were already stored
+	VideosSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "videos_skipped_total",
+		Help:      "Number of videos of the batches skipped as they were already stored.",
+	})
+)
+
+// poolCollector collects the statistics of a pgx pool when the metrics are scraped
+type poolCollector struct {
+	pgxPool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	constructingConns *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+}
+
+// RegisterPool registers the collector of the statistics of the pgx pool
+func RegisterPool(pgxPool *pgxpool.Pool) {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+	prometheus.MustRegister(&poolCollector{
+		pgxPool:           pgxPool,
+		acquiredConns:     desc("acquired_conns", "Number of currently acquired connections of the pool."),
+		idleConns:         desc("idle_conns", "Number of currently idle connections of the pool."),
+		constructingConns: desc("constructing_conns", "Number of connections of the pool being constructed."),
+		totalConns:        desc("total_conns", "Total number of connections of the pool."),
+		maxConns:          desc("max_conns", "Maximum number of connections of the pool."),
+		acquires:          desc("acquires_total", "Number of successful acquires of connections from the pool."),
+		acquireDuration:   desc("acquire_duration_seconds_total", "Total time spent acquiring connections from the pool."),
+		emptyAcquires:     desc("empty_acquires_total", "Number of acquires which waited for a connection as the pool was empty."),
+		canceledAcquires:  desc("canceled_acquires_total", "Number of acquires canceled by their context."),
+	})
+}
+
+func (p *poolCollector) Describe(descs chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(p, descs)
+}
+
+func (p *poolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := p.pgxPool.Stat()
+	metrics <- prometheus.MustNewConstMetric(p.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	metrics <- prometheus.MustNewConstMetric(p.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	metrics <- prometheus.MustNewConstMetric(p.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	metrics <- prometheus.MustNewConstMetric(p.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	metrics <- prometheus.MustNewConstMetric(p.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	metrics <- prometheus.MustNewConstMetric(p.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(p.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	metrics <- prometheus.MustNewConstMetric(p.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(p.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// reconnectDelay is the delay before listening again after the listening connection fails
+const reconnectDelay = 5 * time.Second
+
+// Listener listens to a Postgres notification channel on a single connection and wakes up all of its subscribers
+// on each notification. Subscribers are only woken up, so they must query the changes they are interested in.
+type Listener struct {
+	pgxPool     *pgxpool.Pool
+	channel     string
+	mutex       sync.Mutex
+	subscribers map[chan struct{}]bool
+}
+
+// NewListener creates a listener of the given notification channel, which starts listening once Listen is called.
+func NewListener(pgxPool *pgxpool.Pool, channel string) *Listener {
+	return &Listener{
+		pgxPool:     pgxPool,
+		channel:     channel,
+		subscribers: make(map[chan struct{}]bool),
+	}
+}
+
+// Subscribe returns a channel which receives a value after notifications, along with a function to unsubscribe.
+// Notifications received before the subscriber reads the channel are coalesced into a single value.
+func (l *Listener) Subscribe() (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+	l.mutex.Lock()
+	l.subscribers[wake] = true
+	l.mutex.Unlock()
+	return wake, func() {
+		l.mutex.Lock()
+		delete(l.subscribers, wake)
+		l.mutex.Unlock()
+	}
+}
+
+// Listen listens to the notification channel until the context is done, listening again when the connection fails.
+// Subscribers are woken up after listening again too, as notifications might have been missed meanwhile.
+func (l *Listener) Listen(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Error("Error listening to notifications, listening again", "channel", l.channel, "delay", reconnectDelay.String(), "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := l.pgxPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	// the connection is closed before it is released, so that a connection still listening to the channel is not reused
+	defer conn.Conn().Close(context.Background())
+	if _, err = conn.Exec(ctx, "LISTEN "+l.channel); err != nil {
+		return err
+	}
+	l.wakeSubscribers()
+	for {
+		if _, err = conn.Conn().WaitForNotification(ctx); err != nil {
+			return err
+		}
+		l.wakeSubscribers()
+	}
+}
+
+func (l *Listener) wakeSubscribers() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for wake := range l.subscribers {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
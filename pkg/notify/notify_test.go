@@ -0,0 +1,39 @@
+package notify
+
+import "testing"
+
+func TestWakeSubscribers(t *testing.T) {
+	listener := NewListener(nil, "videos_inserted")
+	first, unsubscribeFirst := listener.Subscribe()
+	second, unsubscribeSecond := listener.Subscribe()
+	defer unsubscribeSecond()
+
+	// notifications which are not read yet are coalesced
+	listener.wakeSubscribers()
+	listener.wakeSubscribers()
+	for name, wake := range map[string]<-chan struct{}{"first": first, "second": second} {
+		select {
+		case <-wake:
+		default:
+			t.Errorf("%s subscriber is not woken up", name)
+		}
+		select {
+		case <-wake:
+			t.Errorf("%s subscriber is woken up twice", name)
+		default:
+		}
+	}
+
+	unsubscribeFirst()
+	listener.wakeSubscribers()
+	select {
+	case <-first:
+		t.Errorf("unsubscribed subscriber is woken up")
+	default:
+	}
+	select {
+	case <-second:
+	default:
+		t.Errorf("second subscriber is not woken up")
+	}
+}
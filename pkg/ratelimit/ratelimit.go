@@ -0,0 +1,185 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is the time after which the bucket of an idle key is removed, it must be longer than the time any of
+// the limits takes to refill a bucket, since the bucket of a key is full again when it is removed
+const idleBucketTTL = time.Hour
+
+// sweepInterval is the interval at which the buckets of idle keys are removed
+const sweepInterval = 10 * time.Minute
+
+// Limit of a token bucket, which holds up to Burst tokens and is refilled at PerMinute tokens per minute.
+// Each request takes a token from the bucket.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// rate returns the refill rate of the bucket in tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Result of taking a token from a bucket. Remaining is the number of tokens left in the bucket, Reset is the time until
+// the bucket is full again, and RetryAfter is the time until the next token when the request is not allowed.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter takes the tokens of the requests from the buckets of the keys
+type Limiter interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// TakeAll takes a token from the buckets of all the keys of a request and returns the most restrictive of their
+// results, false is returned when the limiter failed for all of them. The keys the limiter fails for are logged and
+// skipped, so that the store of the buckets is not a single point of failure.
+func TakeAll(ctx context.Context, limiter Limiter, keys []string, limit Limit) (Result, bool) {
+	var limited *Result
+	for _, key := range keys {
+		r, err := limiter.Take(ctx, key, limit)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error taking a rate limit token", "key", key, "err", err)
+			continue
+		}
+		if limited == nil || isMoreRestrictive(r, *limited) {
+			limited = &r
+		}
+	}
+	if limited == nil {
+		return Result{}, false
+	}
+	return *limited, true
+}
+
+// isMoreRestrictive returns true if the result denies the request or leaves fewer tokens than the other result
+func isMoreRestrictive(result Result, other Result) bool {
+	if result.Allowed != other.Allowed {
+		return !result.Allowed
+	}
+	if !result.Allowed {
+		return result.RetryAfter > other.RetryAfter
+	}
+	return result.Remaining < other.Remaining
+}
+
+// result returns the result of taking a token from a bucket left with the given tokens
+func result(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.rate()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryLimiter keeps the buckets in memory, so the limits apply to each replica of the API separately
+type MemoryLimiter struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryLimiter creates a limiter keeping the buckets in memory
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), lastSweep: time.Now(), now: time.Now}
+}
+
+// Take refills the bucket of the key and takes a token from it if it has one
+func (m *MemoryLimiter) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+math.Max(0, now.Sub(b.updatedAt).Seconds())*limit.rate())
+	b.updatedAt = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return result(limit, b.tokens, allowed), nil
+}
+
+// sweep removes the buckets of the idle keys, the mutex must be held
+func (m *MemoryLimiter) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.Sub(b.updatedAt) >= idleBucketTTL {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// PostgresLimiter keeps the buckets in the rate_limit_buckets table, so the limits hold across the replicas of the API
+type PostgresLimiter struct {
+	pgxPool   *pgxpool.Pool
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+// NewPostgresLimiter creates a limiter keeping the buckets in Postgres
+func NewPostgresLimiter(pgxPool *pgxpool.Pool) *PostgresLimiter {
+	return &PostgresLimiter{pgxPool: pgxPool, lastSweep: time.Now()}
+}
+
+// Take refills the bucket of the key and takes a token from it if it has one, atomically across the replicas
+func (p *PostgresLimiter) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	p.sweepIfDue()
+	var allowed bool
+	var tokens float64
+	row := p.pgxPool.QueryRow(ctx, "SELECT allowed, remaining_tokens FROM rate_limit_take($1, $2, $3)", key, limit.rate(), float64(limit.Burst))
+	if err := row.Scan(&allowed, &tokens); err != nil {
+		return Result{}, err
+	}
+	return result(limit, tokens, allowed), nil
+}
+
+// sweepIfDue removes the buckets of the idle keys in the background, once per sweep interval per replica
+func (p *PostgresLimiter) sweepIfDue() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if time.Since(p.lastSweep) < sweepInterval {
+		return
+	}
+	p.lastSweep = time.Now()
+	go func() {
+		_, err := p.pgxPool.Exec(context.Background(), "DELETE FROM rate_limit_buckets WHERE updated_at < $1", time.Now().UTC().Add(-idleBucketTTL))
+		if err != nil {
+			slog.Error("Error removing idle rate limit buckets", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	// 1 token per second, up to 3 tokens
+	limit := Limit{PerMinute: 60, Burst: 3}
+	take := func(key string) Result {
+		r, err := limiter.Take(context.Background(), key, limit)
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+		return r
+	}
+
+	for i := 2; i >= 0; i-- {
+		r := take("client:1")
+		if !r.Allowed || r.Remaining != i || r.Limit != 3 {
+			t.Fatalf("Take() = %+v, want allowed with %d remaining", r, i)
+		}
+	}
+	r := take("client:1")
+	if r.Allowed || r.Remaining != 0 || r.RetryAfter != time.Second || r.Reset != 3*time.Second {
+		t.Errorf("Take() of an empty bucket = %+v, want not allowed and retry after 1s", r)
+	}
+	// the buckets of the keys are independent
+	if r = take("client:2"); !r.Allowed {
+		t.Errorf("Take() of another key = %+v, want allowed", r)
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	r = take("client:1")
+	if !r.Allowed || r.Remaining != 0 || r.Reset != 2500*time.Millisecond {
+		t.Errorf("Take() after 1.5s = %+v, want allowed with 0.5 tokens left", r)
+	}
+
+	// the bucket is not refilled over the burst
+	now = now.Add(time.Hour)
+	if r = take("client:1"); !r.Allowed || r.Remaining != 2 {
+		t.Errorf("Take() after an hour = %+v, want allowed with 2 remaining", r)
+	}
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limiter.lastSweep = now
+	limit := Limit{PerMinute: 60, Burst: 3}
+	_, _ = limiter.Take(context.Background(), "idle", limit)
+	now = now.Add(idleBucketTTL)
+	_, _ = limiter.Take(context.Background(), "active", limit)
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Errorf("bucket of the idle key is not removed")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Errorf("bucket of the active key is removed")
+	}
+}
@@ -0,0 +1,62 @@
+package simhash
+
+import (
+	"github.com/Gohelraj/youtube-search-api/utils"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// Fingerprint returns the 64-bit SimHash of the text, computed from its words and word bigrams.
+// Near-duplicate texts have fingerprints differing in only a few bits.
+func Fingerprint(text string) uint64 {
+	words := utils.SplitWords(text)
+	features := make([]string, 0, 2*len(words))
+	features = append(features, words...)
+	for i := 1; i < len(words); i++ {
+		features = append(features, words[i-1]+" "+words[i])
+	}
+	var weights [64]int
+	for _, feature := range features {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		hash := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if hash&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var fingerprint uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// Distance returns the number of bits in which the fingerprints differ
+func Distance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Bands returns the 4 16-bit bands of the fingerprint. Fingerprints within a distance of 3
+// share at least one band, so the bands can be used to look up near-duplicate candidates.
+func Bands(fingerprint uint64) [4]int64 {
+	var bands [4]int64
+	for i := range bands {
+		bands[i] = int64((fingerprint >> (16 * i)) & 0xffff)
+	}
+	return bands
+}
+
+// Text returns the text of a video whose fingerprint is used to find its near-duplicates
+func Text(title string, description *string) string {
+	if description == nil {
+		return title
+	}
+	return strings.Join([]string{title, *description}, " ")
+}
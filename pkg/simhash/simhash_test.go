@@ -0,0 +1,45 @@
+package simhash
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	original := Fingerprint("Virat Kohli flicked away for a beautiful six against Australia in the second T20 match #shorts #cricket")
+	tests := []struct {
+		name        string
+		text        string
+		maxDistance int
+		minDistance int
+	}{
+		{
+			name:        "same text",
+			text:        "Virat Kohli flicked away for a beautiful six against Australia in the second T20 match #shorts #cricket",
+			maxDistance: 0,
+		},
+		{
+			name:        "re-upload with different punctuation and case",
+			text:        "VIRAT KOHLI flicked away for a beautiful six against Australia in the second T20 match!! #shorts #cricket",
+			maxDistance: 0,
+		},
+		{
+			name:        "unrelated text",
+			text:        "How to make masala tea at home in 5 minutes with ginger and cardamom",
+			minDistance: 10,
+			maxDistance: 64,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := Distance(original, Fingerprint(tt.text))
+			if distance > tt.maxDistance || distance < tt.minDistance {
+				t.Errorf("Distance() = %v, want between %v and %v", distance, tt.minDistance, tt.maxDistance)
+			}
+		})
+	}
+}
+
+func TestBands(t *testing.T) {
+	bands := Bands(0x0004000300020001)
+	if bands != [4]int64{1, 2, 3, 4} {
+		t.Errorf("Bands() = %v, want [1 2 3 4]", bands)
+	}
+}
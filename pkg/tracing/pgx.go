@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+// QueryLogger records a span of each query logged by pgx, as pgx v4 has no tracing hooks. The span ends when the
+// query is logged and starts its duration earlier, the queries of a batch have no duration as pgx doesn't time them.
+// Only the queries run with the context of a span are recorded, so that the queries of the background jobs which
+// are not traced don't start a trace each. The arguments of the queries are not recorded, as they may be sensitive.
+type QueryLogger struct {
+	tracer trace.Tracer
+}
+
+// NewQueryLogger creates a pgx logger recording the spans of the queries
+func NewQueryLogger() *QueryLogger {
+	return &QueryLogger{tracer: Tracer()}
+}
+
+// queryMessages are the messages of the pgx logs of the queries
+var queryMessages = map[string]bool{
+	"Query":             true,
+	"Exec":              true,
+	"BatchResult.Exec":  true,
+	"BatchResult.Query": true,
+	"BatchResult.Close": true,
+	"CopyFrom":          true,
+}
+
+func (l *QueryLogger) Log(ctx context.Context, _ pgx.LogLevel, msg string, data map[string]interface{}) {
+	if !queryMessages[msg] || !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	end := time.Now()
+	start := end
+	if duration, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-duration)
+	}
+	sql, _ := data["sql"].(string)
+	if msg == "CopyFrom" {
+		sql = "COPY"
+	}
+	attributes := []attribute.KeyValue{semconv.DBSystemPostgreSQL, semconv.DBStatementKey.String(sql)}
+	if rowCount, ok := data["rowCount"].(int); ok {
+		attributes = append(attributes, attribute.Int("db.row_count", rowCount))
+	}
+	_, span := l.tracer.Start(ctx, spanName(sql), trace.WithSpanKind(trace.SpanKindClient), trace.WithTimestamp(start), trace.WithAttributes(attributes...))
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// spanName returns the name of the span of a query, which is its SQL command such as "SELECT" or "INSERT"
+func spanName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "postgres"
+	}
+	return "postgres " + strings.ToUpper(fields[0])
+}
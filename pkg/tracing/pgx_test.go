@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+func TestQueryLogger(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	logger := &QueryLogger{tracer: tracer}
+
+	// the queries run without a span are not recorded
+	logger.Log(context.Background(), pgx.LogLevelInfo, "Query", map[string]interface{}{"sql": "SELECT 1"})
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	logger.Log(ctx, pgx.LogLevelInfo, "Dialing PostgreSQL server", map[string]interface{}{"host": "db"})
+	logger.Log(ctx, pgx.LogLevelInfo, "Query", map[string]interface{}{"sql": "  select * from videos", "args": []interface{}{"secret"}, "time": 30 * time.Millisecond, "rowCount": 2})
+	logger.Log(ctx, pgx.LogLevelError, "Exec", map[string]interface{}{"sql": "DELETE FROM videos", "err": errors.New("deadlock detected")})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want the 2 queries and their parent", len(spans))
+	}
+	query, exec := spans[0], spans[1]
+	if query.Name() != "postgres SELECT" || query.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("query span = %s with parent %s, want postgres SELECT under the parent", query.Name(), query.Parent().SpanID())
+	}
+	if duration := query.EndTime().Sub(query.StartTime()); duration != 30*time.Millisecond {
+		t.Errorf("query span duration = %v, want 30ms", duration)
+	}
+	for _, attribute := range query.Attributes() {
+		if attribute.Key == "db.row_count" && attribute.Value.AsInt64() != 2 {
+			t.Errorf("db.row_count = %d, want 2", attribute.Value.AsInt64())
+		}
+		if attribute.Value.Emit() == "secret" {
+			t.Errorf("query argument is recorded as %s", attribute.Key)
+		}
+	}
+	if exec.Name() != "postgres DELETE" || exec.Status().Code != codes.Error {
+		t.Errorf("exec span = %s with status %v, want postgres DELETE with an error", exec.Name(), exec.Status())
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// ServiceName identifies the spans of the API in the traces
+const ServiceName = "youtube-search-api"
+
+// Exporters of the spans
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Tracer returns the tracer of the spans of the API
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/Gohelraj/youtube-search-api")
+}
+
+// Enabled reports whether the spans are exported, so that the instrumentation which has a cost even without a
+// tracer provider can be skipped
+func Enabled() bool {
+	return config.Conf.Tracing.Exporter != "" && config.Conf.Tracing.Exporter != ExporterNone
+}
+
+// Init sets up the global tracer provider exporting the spans to the configured exporter, and the W3C trace context
+// propagator. The returned function flushes the pending spans and must be called before exiting.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Conf.Tracing.Exporter {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Conf.Tracing.OTLPEndpoint)}
+		if config.Conf.Tracing.OTLPInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Conf.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", config.Conf.Tracing.Exporter, err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName))),
+		// the sampling decision of the parent is kept, so that the traces crossing the queue are complete
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.Conf.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
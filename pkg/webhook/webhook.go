@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Gohelraj/youtube-search-api/api/model"
+	"github.com/Gohelraj/youtube-search-api/api/repository"
+	"github.com/Gohelraj/youtube-search-api/config"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers of a delivery request, the signature lets the receiver verify that the payload is sent by us
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	// firstRetryDelay is the delay before retrying a failed delivery for the first time, it doubles with each failed attempt
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = time.Hour
+	// claimBatchSize is the number of due deliveries attempted concurrently
+	claimBatchSize = 20
+)
+
+// wake wakes up the delivery worker when new deliveries are enqueued, instead of waiting for the next poll
+var wake = make(chan struct{}, 1)
+
+// Sign returns the signature of the payload, which is the hex encoded HMAC-SHA256 of it keyed with the secret, prefixed with "sha256="
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the signed payload to the URL and returns the response status code, which is 0 if no response is received.
+// An error is returned if the request fails or the response status is not 2xx.
+func Send(client *http.Client, url string, secret string, deliveryID int64, event string, payload []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, Sign(secret, payload))
+	request.Header.Set(EventHeader, event)
+	request.Header.Set(DeliveryHeader, strconv.FormatInt(deliveryID, 10))
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// the body is drained so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Backoff returns the delay before the next attempt of a delivery after the given number of failed attempts
+func Backoff(failedAttempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < failedAttempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Enqueue creates a delivery of the newly inserted videos to each webhook matching any of them, the payload of
+// a webhook has only the videos matching its query and filters.
+func Enqueue(ctx context.Context, webhookRepository repository.WebhookRepository, videoRepository repository.VideoRepository, insertedVideos []model.VideoMetadata) error {
+	if len(insertedVideos) == 0 {
+		return nil
+	}
+	webhooks, err := webhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	videoIDs := make([]int64, 0, len(insertedVideos))
+	for _, video := range insertedVideos {
+		videoIDs = append(videoIDs, video.ID)
+	}
+	enqueued := false
+	for _, webhook := range webhooks {
+		videos := insertedVideos
+		if webhook.Query != nil || webhook.Filters != (model.VideoFilters{}) {
+			query := ""
+			if webhook.Query != nil {
+				query = *webhook.Query
+			}
+			matchingIDs, err := videoRepository.FilterVideos(ctx, videoIDs, query, webhook.Filters)
+			if err != nil {
+				return err
+			}
+			videos = matchingVideos(insertedVideos, matchingIDs)
+		}
+		if len(videos) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(model.WebhookPayload{Event: model.WebhookEventVideosInserted, CreatedAt: time.Now().UTC(), Videos: videos})
+		if err != nil {
+			return err
+		}
+		if _, err = webhookRepository.InsertWebhookDelivery(ctx, webhook.ID, model.WebhookEventVideosInserted, payload); err != nil {
+			return err
+		}
+		enqueued = true
+	}
+	if enqueued {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// matchingVideos returns the videos of the given ids
+func matchingVideos(videos []model.VideoMetadata, ids []int64) []model.VideoMetadata {
+	isMatching := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		isMatching[id] = true
+	}
+	matching := []model.VideoMetadata{}
+	for _, video := range videos {
+		if isMatching[video.ID] {
+			matching = append(matching, video)
+		}
+	}
+	return matching
+}
+
+// StartDeliveryWorker attempts the due deliveries whenever new deliveries are enqueued, and at every poll interval
+// for the retries, until the context is done.
+func StartDeliveryWorker(ctx context.Context, pgxPool *pgxpool.Pool) {
+	webhookRepository := repository.NewWebhookRepo(pgxPool)
+	client := &http.Client{Timeout: config.Conf.Webhook.Timeout}
+	ticker := time.NewTicker(config.Conf.Webhook.PollInterval)
+	defer ticker.Stop()
+	for {
+		deliverDue(ctx, webhookRepository, client)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// deliverDue attempts all the due deliveries until the context is done. The claimed deliveries are attempted and
+// recorded even if the context is done meanwhile, so that they don't wait for their lease to expire.
+func deliverDue(ctx context.Context, webhookRepository repository.WebhookRepository, client *http.Client) {
+	for ctx.Err() == nil {
+		// the lease outlasts the attempts, so that a delivery is not claimed again while it is being attempted
+		deliveries, err := webhookRepository.ClaimDueWebhookDeliveries(ctx, claimBatchSize, client.Timeout+time.Minute)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error claiming webhook deliveries", "err", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery model.WebhookDelivery) {
+				defer wg.Done()
+				deliver(context.WithoutCancel(ctx), webhookRepository, client, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+	}
+}
+
+// deliver attempts the delivery and records the attempt, a failed delivery is retried with backoff until it runs out of attempts
+func deliver(ctx context.Context, webhookRepository repository.WebhookRepository, client *http.Client, delivery model.WebhookDelivery) {
+	webhook, err := webhookRepository.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		// the delivery is attempted again once its lease expires
+		slog.ErrorContext(ctx, "Error getting webhook", "webhook_id", delivery.WebhookID, "delivery_id", delivery.ID, "err", err)
+		return
+	}
+	attempt := model.WebhookDeliveryAttempt{AttemptedAt: time.Now().UTC()}
+	statusCode, err := Send(client, webhook.URL, webhook.Secret, delivery.ID, delivery.Event, delivery.Payload)
+	attempt.DurationMs = time.Since(attempt.AttemptedAt).Milliseconds()
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	status := model.WebhookDeliverySucceeded
+	var nextAttemptAt *time.Time
+	if err != nil {
+		errMessage := err.Error()
+		attempt.Error = &errMessage
+		status = model.WebhookDeliveryFailed
+		failedAttempts := delivery.AttemptCount + 1
+		if failedAttempts < config.Conf.Webhook.MaxAttempts {
+			status = model.WebhookDeliveryPending
+			retryAt := time.Now().UTC().Add(Backoff(failedAttempts))
+			nextAttemptAt = &retryAt
+		}
+	}
+	if err = webhookRepository.RecordWebhookDeliveryAttempt(ctx, delivery.ID, attempt, status, nextAttemptAt); err != nil {
+		slog.ErrorContext(ctx, "Error recording attempt of webhook delivery", "delivery_id", delivery.ID, "err", err)
+	}
+}
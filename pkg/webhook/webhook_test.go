@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 test case 2 of RFC 4231
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestSend(t *testing.T) {
+	payload := []byte(`{"event":"videos.inserted","videos":[]}`)
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	statusCode, err := Send(receiver.Client(), receiver.URL, "0123456789abcdef", 42, "videos.inserted", payload)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if statusCode != http.StatusNoContent {
+		t.Errorf("Send() status code = %d, want %d", statusCode, http.StatusNoContent)
+	}
+	if string(receivedBody) != string(payload) {
+		t.Errorf("received body = %s, want %s", receivedBody, payload)
+	}
+	if got, want := received.Header.Get(SignatureHeader), Sign("0123456789abcdef", receivedBody); got != want {
+		t.Errorf("received signature = %q, want %q", got, want)
+	}
+	if got := received.Header.Get(EventHeader); got != "videos.inserted" {
+		t.Errorf("received event = %q, want %q", got, "videos.inserted")
+	}
+	if got := received.Header.Get(DeliveryHeader); got != "42" {
+		t.Errorf("received delivery = %q, want %q", got, "42")
+	}
+	if got := received.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("received content type = %q, want %q", got, "application/json")
+	}
+}
+
+func TestSendFailure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	statusCode, err := Send(receiver.Client(), receiver.URL, "0123456789abcdef", 1, "videos.inserted", []byte("{}"))
+	if err == nil || statusCode != http.StatusServiceUnavailable {
+		t.Errorf("Send() = %d, %v, want %d and an error", statusCode, err, http.StatusServiceUnavailable)
+	}
+
+	// no status code is returned when the receiver is unreachable
+	receiver.Close()
+	statusCode, err = Send(receiver.Client(), receiver.URL, "0123456789abcdef", 1, "videos.inserted", []byte("{}"))
+	if err == nil || statusCode != 0 {
+		t.Errorf("Send() = %d, %v, want 0 and an error", statusCode, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failedAttempts int
+		want           time.Duration
+	}{
+		{failedAttempts: 1, want: 30 * time.Second},
+		{failedAttempts: 2, want: time.Minute},
+		{failedAttempts: 5, want: 8 * time.Minute},
+		{failedAttempts: 8, want: time.Hour},
+		{failedAttempts: 100, want: time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.failedAttempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.failedAttempts, got, tt.want)
+		}
+	}
+}
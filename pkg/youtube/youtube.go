@@ -3,33 +3,78 @@ package youtube
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/Gohelraj/youtube-search-api/api/model"
 	"github.com/Gohelraj/youtube-search-api/api/repository"
 	"github.com/Gohelraj/youtube-search-api/config"
 	"github.com/Gohelraj/youtube-search-api/pkg/ampq"
+	"github.com/Gohelraj/youtube-search-api/pkg/metrics"
+	"github.com/Gohelraj/youtube-search-api/pkg/simhash"
+	"github.com/Gohelraj/youtube-search-api/pkg/tracing"
+	"github.com/Gohelraj/youtube-search-api/pkg/webhook"
 	"github.com/Gohelraj/youtube-search-api/utils"
 	"github.com/jackc/pgx/v4/pgxpool"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/api/youtube/v3"
 )
 
+var (
+	// lastIngestionAt is the time of the last search of the videos which succeeded, in Unix nanoseconds. It starts at
+	// the start of the process so that the ingestion is not reported as stale before its first run.
+	lastIngestionAt atomic.Int64
+	// consumerQueue is the queue the videos are consumed from, once it is connected
+	consumerQueue atomic.Value
+	// consuming is set while the videos are consumed from the queue
+	consuming atomic.Bool
+)
+
+func init() {
+	lastIngestionAt.Store(time.Now().UnixNano())
+}
+
+// LastIngestionAt returns the time of the last search of the videos which succeeded
+func LastIngestionAt() time.Time {
+	return time.Unix(0, lastIngestionAt.Load())
+}
+
+// ConsumerStatus returns an error when the videos are not being consumed from the queue
+func ConsumerStatus() error {
+	queue, ok := consumerQueue.Load().(interface{ IsConnected() bool })
+	if !ok || !queue.IsConnected() {
+		return errors.New("not connected to RabbitMQ")
+	}
+	if !consuming.Load() {
+		return errors.New("not consuming the queue")
+	}
+	return nil
+}
+
 // SearchVideosFromYoutubeAndAddToQueue searches videos from YouTube and push the videos to queue
-func SearchVideosFromYoutubeAndAddToQueue(videoKeyword string, pgxPool *pgxpool.Pool) {
+func SearchVideosFromYoutubeAndAddToQueue(ctx context.Context, videoKeyword string, pgxPool *pgxpool.Pool) {
+	logger := slog.With("keyword", videoKeyword, "api_key_index", activeAPIKeyIndex())
 	youtubeRepository := repository.NewVideoRepo(pgxPool)
-	nextPageToken, publishedAfter, err := youtubeRepository.GetAvailableLastPageToken()
+	nextPageToken, publishedAfter, err := youtubeRepository.GetAvailableLastPageToken(ctx)
 	if err != nil {
-		log.Printf("Error getting next page token: %v", err)
+		logger.ErrorContext(ctx, "Error getting next page token", "err", err)
 		return
 	}
+	logger = logger.With("page_token", nextPageToken)
 	if nextPageToken == "" {
-		publishedAfter, err = youtubeRepository.GetLastPublishedAtDateTime()
+		publishedAfter, err = youtubeRepository.GetLastPublishedAtDateTime(ctx)
 		if err != nil {
-			log.Printf("Error getting last published at date time: %v", err)
+			logger.ErrorContext(ctx, "Error getting last published at date time", "err", err)
 			return
 		}
 		if publishedAfter.IsZero() {
@@ -38,11 +83,13 @@ func SearchVideosFromYoutubeAndAddToQueue(videoKeyword string, pgxPool *pgxpool.
 	}
 	service, err := youtube.NewService(context.Background(), option.WithAPIKey(config.Conf.ActiveGoogleAPIKey))
 	if err != nil {
-		log.Fatalf("Error creating new YouTube client: %v", err)
+		logger.ErrorContext(ctx, "Error creating new YouTube client", "err", err)
 		return
 	}
 	// Prepare the API call.
+	searchCtx, span := startAPISpan(ctx, "search.list", attribute.String("youtube.keyword", videoKeyword), attribute.String("youtube.page_token", nextPageToken))
 	call := service.Search.List([]string{"id,snippet"}).
+		Context(searchCtx).
 		Q(videoKeyword).
 		PageToken(nextPageToken).
 		Order("date").
@@ -52,30 +99,31 @@ func SearchVideosFromYoutubeAndAddToQueue(videoKeyword string, pgxPool *pgxpool.
 
 	// Make the API call to YouTube.
 	response, err := call.Do()
+	endAPISpan(span, "search.list", metrics.SearchListQuotaUnits, err)
 	if err != nil {
 		if apiError, ok := err.(*googleapi.Error); ok {
 			// Forbidden error is returned when the API key quota exhausted.
 			if apiError.Code == http.StatusForbidden {
-				retryWithNewAPIKeyWhenForbidden(videoKeyword, pgxPool)
+				retryWithNewAPIKeyWhenForbidden(ctx, videoKeyword, pgxPool)
 			}
 		}
-		log.Printf("Error making YouTube API call: %v", err)
+		logger.ErrorContext(ctx, "Error making YouTube API call", "err", err)
 		return
 	}
 	if response == nil {
-		log.Printf("API call failed: %v", err)
+		logger.ErrorContext(ctx, "API call failed", "err", err)
 		return
 	}
 
 	if response.HTTPStatusCode == http.StatusForbidden {
-		retryWithNewAPIKeyWhenForbidden(videoKeyword, pgxPool)
+		retryWithNewAPIKeyWhenForbidden(ctx, videoKeyword, pgxPool)
 	}
 
 	var videos []model.VideoMetadata
 	for _, item := range response.Items {
 		publishedAt, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
 		if err != nil {
-			log.Printf("Error parsing publishedAt: %v", err)
+			logger.WarnContext(ctx, "Error parsing publishedAt", "youtube_id", item.Id.VideoId, "err", err)
 			continue
 		}
 		videos = append(videos, model.VideoMetadata{
@@ -84,78 +132,344 @@ func SearchVideosFromYoutubeAndAddToQueue(videoKeyword string, pgxPool *pgxpool.
 			Description:  &item.Snippet.Description,
 			PublishedAt:  publishedAt,
 			ThumbnailURL: &item.Snippet.Thumbnails.Medium.Url,
+			ChannelID:    &item.Snippet.ChannelId,
+			ChannelTitle: &item.Snippet.ChannelTitle,
+			Keyword:      &videoKeyword,
 		})
 	}
 
 	if len(videos) > 0 {
+		// search results don't include the video's language, duration and views, so they are fetched from the videos list API
+		if _, err := addVideoDetails(ctx, service, videos); err != nil {
+			logger.ErrorContext(ctx, "Error fetching video details", "err", err)
+		}
 		youtubeVideosQueue := ampq.NewQueue(config.Conf.Ampq.Url, config.Conf.Ampq.QueueName)
 		videosData, err := json.Marshal(videos)
 		if err != nil {
-			log.Printf("Error marshalling videos: %v", err)
+			logger.ErrorContext(ctx, "Error marshalling videos", "err", err)
 			return
 		}
-		youtubeVideosQueue.Send(videosData)
-		log.Println("Queued youtube videos")
+		youtubeVideosQueue.Send(ctx, videosData)
+		logger.InfoContext(ctx, "Queued youtube videos", "videos", len(videos), "published_after", publishedAfter)
 	}
 
 	// Mark last used page token as used to avoid using it again.
-	go youtubeRepository.MarkPageTokenAsUsed(nextPageToken)
+	go youtubeRepository.MarkPageTokenAsUsed(ctx, nextPageToken)
 
 	if response.NextPageToken != "" {
 		// Store next page token to be used in next search.
-		err := youtubeRepository.InsertNextPageToken(response.NextPageToken, publishedAfter)
+		err := youtubeRepository.InsertNextPageToken(ctx, response.NextPageToken, publishedAfter)
 		if err != nil {
-			log.Printf("Error inserting next page token: %v", err)
+			logger.ErrorContext(ctx, "Error inserting next page token", "next_page_token", response.NextPageToken, "err", err)
 			return
 		}
 	}
+	lastIngestionAt.Store(time.Now().UnixNano())
 }
 
-// ProcessYoutubeVideosFromQueue processes videos from queue and inserts them into database
-func ProcessYoutubeVideosFromQueue(pgxPool *pgxpool.Pool) {
+// addVideoDetails fetches details of the given videos which are not available in search results and adds them to the videos.
+// The snippet of the videos which is available in search results is only set when the video doesn't have it yet.
+// It returns the YouTube ids of the videos which are not found on YouTube.
+func addVideoDetails(ctx context.Context, service *youtube.Service, videos []model.VideoMetadata) ([]string, error) {
+	videoIDs := make([]string, 0, len(videos))
+	for _, video := range videos {
+		videoIDs = append(videoIDs, video.YoutubeID)
+	}
+	ctx, span := startAPISpan(ctx, "videos.list", attribute.Int("youtube.videos", len(videoIDs)))
+	response, err := service.Videos.List([]string{"snippet", "contentDetails", "statistics"}).Context(ctx).Id(videoIDs...).MaxResults(50).Do()
+	endAPISpan(span, "videos.list", metrics.VideosListQuotaUnits, err)
+	if err != nil {
+		return nil, err
+	}
+	detailsByID := make(map[string]*youtube.Video, len(response.Items))
+	for _, item := range response.Items {
+		detailsByID[item.Id] = item
+	}
+	var notFound []string
+	for i := range videos {
+		details, ok := detailsByID[videos[i].YoutubeID]
+		if !ok {
+			notFound = append(notFound, videos[i].YoutubeID)
+			continue
+		}
+		if details.ContentDetails != nil {
+			durationSeconds, err := utils.ParseISO8601Duration(details.ContentDetails.Duration)
+			if err == nil {
+				videos[i].DurationSeconds = &durationSeconds
+			}
+		}
+		if details.Statistics != nil {
+			viewCount := int64(details.Statistics.ViewCount)
+			videos[i].ViewCount = &viewCount
+		}
+		if details.Snippet == nil {
+			continue
+		}
+		addSnippet(&videos[i], details.Snippet)
+		// language of the title and description is preferred, as that is what gets indexed for search
+		language := details.Snippet.DefaultLanguage
+		if language == "" {
+			language = details.Snippet.DefaultAudioLanguage
+		}
+		if language != "" {
+			videos[i].Language = &language
+		}
+	}
+	return notFound, nil
+}
+
+// addSnippet sets the title, description, publish time, thumbnail and channel of the video from its snippet,
+// unless the video already has them
+func addSnippet(video *model.VideoMetadata, snippet *youtube.VideoSnippet) {
+	if video.Title == "" {
+		video.Title = snippet.Title
+	}
+	if video.Description == nil {
+		description := snippet.Description
+		video.Description = &description
+	}
+	if video.PublishedAt.IsZero() {
+		if publishedAt, err := time.Parse(time.RFC3339, snippet.PublishedAt); err == nil {
+			video.PublishedAt = publishedAt
+		}
+	}
+	if video.ThumbnailURL == nil && snippet.Thumbnails != nil && snippet.Thumbnails.Medium != nil {
+		thumbnailURL := snippet.Thumbnails.Medium.Url
+		video.ThumbnailURL = &thumbnailURL
+	}
+	if video.ChannelID == nil {
+		channelID := snippet.ChannelId
+		video.ChannelID = &channelID
+	}
+	if video.ChannelTitle == nil {
+		channelTitle := snippet.ChannelTitle
+		video.ChannelTitle = &channelTitle
+	}
+}
+
+// EnrichVideos fetches the metadata of the given videos from the videos list API with the active API key, e.g. for
+// the videos imported with their YouTube id only. At most 50 videos can be enriched at once.
+// It returns the YouTube ids of the videos which are not found on YouTube.
+func EnrichVideos(ctx context.Context, videos []model.VideoMetadata) ([]string, error) {
+	service, err := youtube.NewService(context.Background(), option.WithAPIKey(config.Conf.ActiveGoogleAPIKey))
+	if err != nil {
+		return nil, err
+	}
+	return addVideoDetails(ctx, service, videos)
+}
+
+// InsertVideos inserts a batch of videos clustering their near-duplicates, and notifies the saved searches and
+// webhooks of the inserted videos. Videos already stored are skipped, and only the inserted videos are returned.
+// Notification errors are only logged, as the videos are already inserted.
+func InsertVideos(ctx context.Context, videoRepository repository.VideoRepository, savedSearchRepository repository.SavedSearchRepository,
+	webhookRepository repository.WebhookRepository, videos []model.VideoMetadata) ([]model.VideoMetadata, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "InsertVideos", trace.WithAttributes(attribute.Int("videos", len(videos))))
+	defer span.End()
+	start := time.Now()
+	if err := clusterNearDuplicates(ctx, videoRepository, videos); err != nil {
+		err = fmt.Errorf("clustering near-duplicate videos: %w", err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	insertedVideos, err := videoRepository.InsertVideos(ctx, videos)
+	if err != nil {
+		err = fmt.Errorf("inserting videos: %w", err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("videos.inserted", len(insertedVideos)))
+	metrics.InsertVideosDuration.Observe(time.Since(start).Seconds())
+	metrics.VideosInserted.Add(float64(len(insertedVideos)))
+	metrics.VideosSkipped.Add(float64(len(videos) - len(insertedVideos)))
+	matchSavedSearches(ctx, savedSearchRepository, insertedVideos)
+	if err = webhook.Enqueue(ctx, webhookRepository, videoRepository, insertedVideos); err != nil {
+		slog.ErrorContext(ctx, "Error enqueuing webhook deliveries", "err", err)
+	}
+	return insertedVideos, nil
+}
+
+// ProcessYoutubeVideosFromQueue processes videos from queue and inserts them into database until the context is done,
+// the message being processed is acked or nacked before the connection is closed
+func ProcessYoutubeVideosFromQueue(ctx context.Context, pgxPool *pgxpool.Pool) {
 	youtubeVideosQueue := ampq.NewQueue(config.Conf.Ampq.Url, config.Conf.Ampq.QueueName)
+	consumerQueue.Store(youtubeVideosQueue)
 	queueMessages, err := youtubeVideosQueue.Consumer()
 	if err != nil {
-		log.Printf("Error getting queue messages: %v", err)
+		slog.Error("Error getting queue messages", "queue", config.Conf.Ampq.QueueName, "err", err)
 		return
 	}
-	for queueMessage := range queueMessages {
-		var videos []model.VideoMetadata
-		err := json.Unmarshal(queueMessage.Body, &videos)
-		if err != nil {
-			log.Printf("Error unmarshalling videos: %v", err)
-			continue
+	consuming.Store(true)
+	// the deliveries channel is closed when the connection is lost, which the readiness check reports
+	defer consuming.Store(false)
+	for {
+		select {
+		case <-ctx.Done():
+			if err := youtubeVideosQueue.Close(); err != nil {
+				slog.Error("Error closing queue connection", "queue", config.Conf.Ampq.QueueName, "err", err)
+			}
+			return
+		case queueMessage, ok := <-queueMessages:
+			if !ok {
+				return
+			}
+			processQueueMessage(pgxPool, queueMessage)
 		}
-		youtubeRepository := repository.NewVideoRepo(pgxPool)
-		err = youtubeRepository.InsertVideos(videos)
-		if err != nil {
-			// if error occurred while inserting videos data into database, then retry the request
-			_ = queueMessage.Nack(false, true)
-			log.Printf("Error inserting videos: %v", err)
+	}
+}
+
+// processQueueMessage inserts the videos of the message into database, the message is nacked to be retried when
+// inserting them fails
+func processQueueMessage(pgxPool *pgxpool.Pool, queueMessage amqp.Delivery) {
+	queueName := config.Conf.Ampq.QueueName
+	metrics.QueueMessagesConsumed.WithLabelValues(queueName).Inc()
+	ctx, span := ampq.StartConsumeSpan(queueName, queueMessage)
+	defer span.End()
+	if !queueMessage.Timestamp.IsZero() {
+		metrics.QueueLag.WithLabelValues(queueName).Observe(time.Since(queueMessage.Timestamp).Seconds())
+	}
+	var videos []model.VideoMetadata
+	err := json.Unmarshal(queueMessage.Body, &videos)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error unmarshalling videos", "queue", queueName, "err", err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	_, err = InsertVideos(ctx, repository.NewVideoRepo(pgxPool), repository.NewSavedSearchRepo(pgxPool), repository.NewWebhookRepo(pgxPool), videos)
+	if err != nil {
+		// if error occurred while inserting videos data into database, then retry the request
+		_ = queueMessage.Nack(false, true)
+		metrics.QueueMessagesNacked.WithLabelValues(queueName).Inc()
+		slog.ErrorContext(ctx, "Error inserting videos", "queue", queueName, "videos", len(videos), "err", err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	// if no error occurred while inserting videos data into database, then ack the message
+	if err = queueMessage.Ack(false); err != nil {
+		slog.ErrorContext(ctx, "Error acknowledging videos", "queue", queueName, "err", err)
+		return
+	}
+	metrics.QueueMessagesAcked.WithLabelValues(queueName).Inc()
+}
+
+// activeAPIKeyIndex returns the index of the active API key in the configured keys, which identifies the key in the
+// logs, spans and metrics without exposing it
+func activeAPIKeyIndex() int {
+	return utils.GetIndexOf(config.Conf.ActiveGoogleAPIKey, config.Conf.GoogleAPIKeys)
+}
+
+// startAPISpan starts the span of a call to the YouTube Data API made with the active API key
+func startAPISpan(ctx context.Context, endpoint string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	attributes = append(attributes, attribute.Int("youtube.api_key_index", activeAPIKeyIndex()))
+	return tracing.Tracer().Start(ctx, "youtube "+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+// endAPISpan ends the span of a call to the YouTube Data API, and records the call and the quota units it spent.
+// The API key is identified by its index in the configured keys, so that the keys are not exposed by the metrics.
+func endAPISpan(span trace.Span, endpoint string, quotaUnits int, err error) {
+	defer span.End()
+	key := strconv.Itoa(activeAPIKeyIndex())
+	metrics.YoutubeAPICalls.WithLabelValues(key, endpoint).Inc()
+	// failed calls spend the quota too
+	metrics.YoutubeAPIQuotaUnits.WithLabelValues(key, endpoint).Add(float64(quotaUnits))
+	if err != nil {
+		code := 0
+		if apiError, ok := err.(*googleapi.Error); ok {
+			code = apiError.Code
+		}
+		metrics.YoutubeAPIErrors.WithLabelValues(key, endpoint, strconv.Itoa(code)).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// matchSavedSearches records the newly inserted videos matching each of the saved searches
+func matchSavedSearches(ctx context.Context, savedSearchRepository repository.SavedSearchRepository, insertedVideos []model.VideoMetadata) {
+	if len(insertedVideos) == 0 {
+		return
+	}
+	videoIDs := make([]int64, 0, len(insertedVideos))
+	for _, video := range insertedVideos {
+		videoIDs = append(videoIDs, video.ID)
+	}
+	savedSearches, err := savedSearchRepository.GetSavedSearches(ctx, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting saved searches", "err", err)
+		return
+	}
+	for _, savedSearch := range savedSearches {
+		if _, err := savedSearchRepository.InsertSavedSearchMatches(ctx, savedSearch, videoIDs); err != nil {
+			slog.ErrorContext(ctx, "Error matching saved search", "saved_search_id", savedSearch.ID, "err", err)
+		}
+	}
+}
+
+// nearDuplicateDistance is the maximum number of bits in which the fingerprints of near-duplicate videos differ
+const nearDuplicateDistance = 3
+
+// minFingerprintWords is the minimum number of words of a video's title and description to cluster it, as the
+// fingerprints of short texts like "#shorts #cricket" collide too often
+const minFingerprintWords = 4
+
+// clusterNearDuplicates sets the fingerprint of the videos, and the video each of them is a near-duplicate of,
+// looking up the stored videos first and then the videos earlier in the batch.
+func clusterNearDuplicates(ctx context.Context, youtubeRepository repository.VideoRepository, videos []model.VideoMetadata) error {
+	var bands [4][]int64
+	for i := range videos {
+		text := simhash.Text(videos[i].Title, videos[i].Description)
+		if len(utils.SplitWords(text)) < minFingerprintWords {
 			continue
 		}
-		// if no error occurred while inserting videos data into database, then ack the message
-		err = queueMessage.Ack(false)
-		if err != nil {
-			log.Printf("Error inserting videos: %v", err)
+		fingerprint := simhash.Fingerprint(text)
+		storedFingerprint := int64(fingerprint)
+		videos[i].Fingerprint = &storedFingerprint
+		for band, value := range simhash.Bands(fingerprint) {
+			bands[band] = append(bands[band], value)
+		}
+	}
+	if len(bands[0]) == 0 {
+		return nil
+	}
+	candidates, err := youtubeRepository.GetVideoFingerprints(ctx, bands)
+	if err != nil {
+		return err
+	}
+	isNearDuplicate := func(a int64, b int64) bool {
+		return simhash.Distance(uint64(a), uint64(b)) <= nearDuplicateDistance
+	}
+	for i := range videos {
+		if videos[i].Fingerprint == nil {
 			continue
 		}
+		for _, candidate := range candidates {
+			if candidate.YoutubeID != videos[i].YoutubeID && isNearDuplicate(*videos[i].Fingerprint, candidate.Fingerprint) {
+				duplicateOf := candidate.YoutubeID
+				videos[i].DuplicateOf = &duplicateOf
+				break
+			}
+		}
+		for j := 0; j < i && videos[i].DuplicateOf == nil; j++ {
+			if videos[j].Fingerprint != nil && videos[j].YoutubeID != videos[i].YoutubeID && isNearDuplicate(*videos[i].Fingerprint, *videos[j].Fingerprint) {
+				duplicateOf := videos[j].YoutubeID
+				videos[i].DuplicateOf = &duplicateOf
+			}
+		}
 	}
+	return nil
 }
 
 // retryWithNewAPIKeyWhenForbidden retries the request with new API key when forbidden error is returned
-func retryWithNewAPIKeyWhenForbidden(videoKeyword string, pgxPool *pgxpool.Pool) {
+func retryWithNewAPIKeyWhenForbidden(ctx context.Context, videoKeyword string, pgxPool *pgxpool.Pool) {
 	// Retry the request with new API key.
 	apiKeyIndex := utils.GetIndexOf(config.Conf.ActiveGoogleAPIKey, config.Conf.GoogleAPIKeys)
 	if apiKeyIndex+1 < len(config.Conf.GoogleAPIKeys) {
 		config.Conf.ActiveGoogleAPIKey = config.Conf.GoogleAPIKeys[apiKeyIndex+1]
-		log.Printf("Retrying with new API key")
-		SearchVideosFromYoutubeAndAddToQueue(videoKeyword, pgxPool)
+		slog.WarnContext(ctx, "Retrying with new API key", "keyword", videoKeyword, "api_key_index", apiKeyIndex+1)
+		SearchVideosFromYoutubeAndAddToQueue(ctx, videoKeyword, pgxPool)
 	} else {
-		log.Printf("Retrying with rotating API keys")
+		slog.WarnContext(ctx, "Retrying with rotating API keys", "keyword", videoKeyword, "api_key_index", 0)
 		// If all API keys are used, then retry the request with first API key.
 		// This will ensure that the API key rotation will continue.
 		config.Conf.ActiveGoogleAPIKey = config.Conf.GoogleAPIKeys[0]
-		SearchVideosFromYoutubeAndAddToQueue(videoKeyword, pgxPool)
+		SearchVideosFromYoutubeAndAddToQueue(ctx, videoKeyword, pgxPool)
 	}
 }
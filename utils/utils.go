@@ -1,5 +1,13 @@
 package utils
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 // GetIndexOf returns the index of the given element in the given slice
 func GetIndexOf(element string, data []string) int {
 	for index, value := range data {
@@ -9,3 +17,131 @@ func GetIndexOf(element string, data []string) int {
 	}
 	return -1
 }
+
+// SplitWords splits the given string into lower cased words, ignoring punctuation and symbols
+func SplitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// textSearchConfigs maps ISO 639-1 language codes to the built-in Postgres text search configurations, which
+// include hindi, armenian, basque, catalan, serbian and yiddish since PostgreSQL 14
+var textSearchConfigs = map[string]string{
+	"ar": "arabic",
+	"ca": "catalan",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"eu": "basque",
+	"fi": "finnish",
+	"fr": "french",
+	"ga": "irish",
+	"hi": "hindi",
+	"hu": "hungarian",
+	"hy": "armenian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"ne": "nepali",
+	"nl": "dutch",
+	"no": "norwegian",
+	"nb": "norwegian",
+	"nn": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sr": "serbian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+	"yi": "yiddish",
+}
+
+// TextSearchConfig returns the Postgres text search configuration of the given BCP-47 language tag (e.g. "pt-BR").
+// ok is false if there is no stemming configuration for the language.
+func TextSearchConfig(language string) (config string, ok bool) {
+	primary := strings.ToLower(strings.SplitN(strings.ReplaceAll(language, "_", "-"), "-", 2)[0])
+	config, ok = textSearchConfigs[primary]
+	return config, ok
+}
+
+// VideoTextSearchConfig returns the text search configuration used to index a video of the given language.
+// Videos of unknown language are indexed in english, and videos of languages without a stemming configuration
+// (e.g. japanese) are indexed without stemming so that their words are not mangled by the english stemmer.
+func VideoTextSearchConfig(language *string) string {
+	if language == nil || *language == "" {
+		return "english"
+	}
+	if config, ok := TextSearchConfig(*language); ok {
+		return config
+	}
+	return "simple"
+}
+
+// MaxSynonymTermWords is the maximum number of words of a search term having synonyms
+const MaxSynonymTermWords = 4
+
+// SearchTerms returns all the phrases of up to MaxSynonymTermWords consecutive words, which can be search terms having synonyms
+func SearchTerms(words []string) []string {
+	var terms []string
+	for start := range words {
+		for end := start + 1; end <= len(words) && end-start <= MaxSynonymTermWords; end++ {
+			terms = append(terms, strings.Join(words[start:end], " "))
+		}
+	}
+	return terms
+}
+
+// ExpandSynonyms groups the words into search terms, preferring the longest terms having synonyms, and returns
+// the phrases each term can be matched with: the term itself followed by its synonyms. Stopwords are dropped.
+func ExpandSynonyms(words []string, synonyms map[string][]string, stopwords map[string]bool) [][]string {
+	var expanded [][]string
+	for start := 0; start < len(words); {
+		matched := false
+		end := start + MaxSynonymTermWords
+		if end > len(words) {
+			end = len(words)
+		}
+		for ; end > start; end-- {
+			term := strings.Join(words[start:end], " ")
+			if termSynonyms, ok := synonyms[term]; ok {
+				expanded = append(expanded, append([]string{term}, termSynonyms...))
+				start, matched = end, true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if !stopwords[words[start]] {
+			expanded = append(expanded, []string{words[start]})
+		}
+		start++
+	}
+	return expanded
+}
+
+var iso8601DurationRegex = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseISO8601Duration returns the number of seconds of an ISO 8601 duration as returned by YouTube, e.g. "PT1H4M13S"
+func ParseISO8601Duration(duration string) (int, error) {
+	matches := iso8601DurationRegex.FindStringSubmatch(duration)
+	if matches == nil || duration == "P" || strings.HasSuffix(duration, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", duration)
+	}
+	seconds := 0
+	for i, unit := range []int{24 * 60 * 60, 60 * 60, 60, 1} {
+		if matches[i+1] == "" {
+			continue
+		}
+		value, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return 0, err
+		}
+		seconds += value * unit
+	}
+	return seconds, nil
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestGetIndexOf(t *testing.T) {
 	type args struct {
@@ -37,3 +40,121 @@ func TestGetIndexOf(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{
+			name: "success",
+			s:    "How to make Tea?",
+			want: []string{"how", "to", "make", "tea"},
+		},
+		{
+			name: "hashtags",
+			s:    "#Cricket #shorts, IND vs WI",
+			want: []string{"cricket", "shorts", "ind", "vs", "wi"},
+		},
+		{
+			name: "empty",
+			s:    " ?! ",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitWords(tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitWords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVideoTextSearchConfig(t *testing.T) {
+	language := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		language *string
+		want     string
+	}{
+		{name: "unknown", language: nil, want: "english"},
+		{name: "region subtag", language: language("pt-BR"), want: "portuguese"},
+		{name: "spanish", language: language("es"), want: "spanish"},
+		{name: "hindi", language: language("hi-IN"), want: "hindi"},
+		{name: "no stemming support", language: language("ja"), want: "simple"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VideoTextSearchConfig(tt.language); got != tt.want {
+				t.Errorf("VideoTextSearchConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandSynonyms(t *testing.T) {
+	synonyms := map[string][]string{
+		"soccer":      {"football"},
+		"ipl":         {"indian premier league"},
+		"ipl auction": {"ipl mega auction"},
+	}
+	stopwords := map[string]bool{"shorts": true}
+	tests := []struct {
+		name  string
+		words []string
+		want  [][]string
+	}{
+		{
+			name:  "single word synonym",
+			words: []string{"soccer", "highlights"},
+			want:  [][]string{{"soccer", "football"}, {"highlights"}},
+		},
+		{
+			name:  "longest term is preferred",
+			words: []string{"ipl", "auction", "shorts"},
+			want:  [][]string{{"ipl auction", "ipl mega auction"}},
+		},
+		{
+			name:  "term without synonyms",
+			words: []string{"ipl", "final"},
+			want:  [][]string{{"ipl", "indian premier league"}, {"final"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandSynonyms(tt.words, synonyms, stopwords); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandSynonyms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseISO8601Duration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration string
+		want     int
+		wantErr  bool
+	}{
+		{name: "minutes and seconds", duration: "PT4M13S", want: 253},
+		{name: "hours", duration: "PT1H0M5S", want: 3605},
+		{name: "days", duration: "P1DT2H", want: 93600},
+		{name: "live stream", duration: "P0D", want: 0},
+		{name: "invalid", duration: "4M13S", wantErr: true},
+		{name: "empty", duration: "PT", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseISO8601Duration(tt.duration)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseISO8601Duration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseISO8601Duration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}